@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+)
+
+// AccountService manages the settings of the account itself.
+type AccountService struct {
+	client *Client
+}
+
+func (s *AccountService) Get(ctx context.Context) (*Account, error) {
+	var res AccountWrap
+	err := s.client.Get(ctx, []string{"account"}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Account, nil
+}
+
+// Update changes the account's settings. Fields left empty are not
+// changed. Setting OwnerID transfers ownership of the account to
+// another user.
+func (s *AccountService) Update(ctx context.Context, account *Account) error {
+	req := &AccountWrap{
+		Account: *account,
+	}
+
+	if s.client.cache != nil {
+		// A change of owner changes the roles of two users.
+		s.client.cache.Invalidate("users")
+	}
+	return s.client.Put(ctx, []string{"account"}, req, nil)
+}
+
+type Account struct {
+	ID               int    `json:"id,omitempty"`
+	Name             string `json:"name,omitempty"`
+	ThirdLevelDomain string `json:"third_level_domain,omitempty"`
+	TimeZone         string `json:"time_zone,omitempty"`
+	OwnerID          int    `json:"owner_id,omitempty"`
+}
+
+type AccountWrap struct {
+	Account Account `json:"account"`
+}
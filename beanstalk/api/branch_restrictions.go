@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// BranchRestrictionsService manages restrictions on who may push to
+// particular branches of a repository.
+type BranchRestrictionsService struct {
+	client *Client
+}
+
+func (s *BranchRestrictionsService) Get(ctx context.Context, repositoryId int, id int) (*BranchRestriction, error) {
+	var res BranchRestrictionWrap
+	err := s.client.Get(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branch_restrictions", strconv.Itoa(id)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.BranchRestriction, nil
+}
+
+func (s *BranchRestrictionsService) Create(ctx context.Context, repositoryId int, restriction *BranchRestriction) (*BranchRestriction, error) {
+	req := &BranchRestrictionWrap{
+		BranchRestriction: *restriction,
+	}
+	res := &BranchRestrictionWrap{}
+
+	err := s.client.Post(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branch_restrictions"}, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.BranchRestriction, nil
+}
+
+func (s *BranchRestrictionsService) Update(ctx context.Context, repositoryId int, id int, restriction *BranchRestriction) error {
+	req := &BranchRestrictionWrap{
+		BranchRestriction: *restriction,
+	}
+
+	return s.client.Put(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branch_restrictions", strconv.Itoa(id)}, req, nil)
+}
+
+func (s *BranchRestrictionsService) Delete(ctx context.Context, repositoryId int, id int) error {
+	return s.client.Delete(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branch_restrictions", strconv.Itoa(id)})
+}
+
+type BranchRestriction struct {
+	ID         int    `json:"id,omitempty"`
+	BranchName string `json:"branch_name"`
+	UserIDs    []int  `json:"user_ids"`
+	TeamIDs    []int  `json:"team_ids"`
+}
+
+type BranchRestrictionWrap struct {
+	BranchRestriction BranchRestriction `json:"branch_restriction"`
+}
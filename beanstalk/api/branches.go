@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// BranchesService manages the branches of git repositories.
+type BranchesService struct {
+	client *Client
+}
+
+// Each calls fn for each branch in a repository, stopping early if fn
+// returns false or an error.
+func (s *BranchesService) Each(ctx context.Context, repositoryId int, fn func(*Branch) (bool, error)) error {
+	var res []BranchWrap
+	return s.client.GetPages(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branches"}, nil, &res, func() (bool, error) {
+		for i := range res {
+			more, err := fn(&res[i].Branch)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Get returns the branch with the given name, or a NotFoundError if there
+// is no such branch.
+func (s *BranchesService) Get(ctx context.Context, repositoryId int, name string) (*Branch, error) {
+	var found *Branch
+	err := s.Each(ctx, repositoryId, func(branch *Branch) (bool, error) {
+		if branch.Name == name {
+			b := *branch
+			found = &b
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, &NotFoundError{}
+	}
+	return found, nil
+}
+
+// Create creates a new branch from the given ref, which may be a branch
+// name or a commit hash. If ref is empty the repository's default branch
+// is used.
+func (s *BranchesService) Create(ctx context.Context, repositoryId int, name string, ref string) error {
+	req := &BranchWrap{
+		Branch: Branch{
+			Name: name,
+			Ref:  ref,
+		},
+	}
+
+	return s.client.Post(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branches"}, req, nil)
+}
+
+func (s *BranchesService) Delete(ctx context.Context, repositoryId int, name string) error {
+	return s.client.Delete(ctx, []string{"repositories", strconv.Itoa(repositoryId), "branches", name})
+}
+
+type Branch struct {
+	Name     string `json:"name"`
+	Ref      string `json:"ref,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+type BranchWrap struct {
+	Branch Branch `json:"branch"`
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// listCache holds the complete contents of some of the account's
+// collections, so that reads of many individual items can be served from
+// a single paged listing rather than a request each. It is loaded lazily,
+// one collection at a time, and items are forgotten when they are written
+// so that subsequent reads go back to the API.
+type listCache struct {
+	mu          sync.Mutex
+	collections map[string]*cachedCollection
+}
+
+type cachedCollection struct {
+	mu     sync.Mutex
+	loaded bool
+	items  map[int]interface{}
+}
+
+// cacheLoader lists an entire collection, calling add for each item.
+type cacheLoader func(ctx context.Context, add func(id int, item interface{})) error
+
+func newListCache() *listCache {
+	return &listCache{
+		collections: map[string]*cachedCollection{},
+	}
+}
+
+func (c *listCache) collection(name string) *cachedCollection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	coll, ok := c.collections[name]
+	if !ok {
+		coll = &cachedCollection{}
+		c.collections[name] = coll
+	}
+	return coll
+}
+
+// Get returns the item with the given id from the named collection,
+// loading the whole collection first if necessary. The second return
+// value is false if the item is not in the cache, in which case the
+// caller should request it directly.
+func (c *listCache) Get(ctx context.Context, name string, id int, load cacheLoader) (interface{}, bool, error) {
+	coll := c.collection(name)
+
+	// Holding the collection's lock while loading ensures that concurrent
+	// readers wait for a single listing rather than each starting their own.
+	coll.mu.Lock()
+	defer coll.mu.Unlock()
+
+	if !coll.loaded {
+		items := map[int]interface{}{}
+		err := load(ctx, func(id int, item interface{}) {
+			items[id] = item
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		coll.items = items
+		coll.loaded = true
+	}
+
+	item, ok := coll.items[id]
+	return item, ok, nil
+}
+
+// Forget removes a single item from the named collection, so that the
+// next read of it will go to the API.
+func (c *listCache) Forget(name string, id int) {
+	coll := c.collection(name)
+	coll.mu.Lock()
+	defer coll.mu.Unlock()
+
+	delete(coll.items, id)
+}
+
+// Invalidate discards the entire named collection, so that it will be
+// listed again when next needed.
+func (c *listCache) Invalidate(name string) {
+	coll := c.collection(name)
+	coll.mu.Lock()
+	defer coll.mu.Unlock()
+
+	coll.loaded = false
+	coll.items = nil
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// countingLoader returns a cacheLoader that adds the given items, keyed by
+// their own values, and counts how many times it was called.
+func countingLoader(calls *int, items ...int) cacheLoader {
+	return func(ctx context.Context, add func(int, interface{})) error {
+		*calls++
+		for _, item := range items {
+			add(item, item)
+		}
+		return nil
+	}
+}
+
+func TestListCacheLoadsOnce(t *testing.T) {
+	c := newListCache()
+	calls := 0
+	load := countingLoader(&calls, 1, 2)
+
+	for _, id := range []int{1, 2, 1} {
+		item, ok, err := c.Get(context.Background(), "things", id, load)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || item.(int) != id {
+			t.Errorf("Get(%d) returned %v, %v", id, item, ok)
+		}
+	}
+
+	_, ok, err := c.Get(context.Background(), "things", 3, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Get(3) found an item that isn't in the collection")
+	}
+
+	if calls != 1 {
+		t.Errorf("collection was loaded %d times, want once", calls)
+	}
+}
+
+func TestListCacheLoadsConcurrentReadersOnce(t *testing.T) {
+	c := newListCache()
+	var mu sync.Mutex
+	calls := 0
+	load := func(ctx context.Context, add func(int, interface{})) error {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		add(1, 1)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(context.Background(), "things", 1, load)
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("collection was loaded %d times, want once", calls)
+	}
+}
+
+func TestListCacheForget(t *testing.T) {
+	c := newListCache()
+	calls := 0
+	load := countingLoader(&calls, 1, 2)
+
+	c.Get(context.Background(), "things", 1, load)
+	c.Forget("things", 1)
+
+	if _, ok, _ := c.Get(context.Background(), "things", 1, load); ok {
+		t.Errorf("forgotten item is still cached")
+	}
+	if _, ok, _ := c.Get(context.Background(), "things", 2, load); !ok {
+		t.Errorf("forgetting one item also forgot another")
+	}
+	if calls != 1 {
+		t.Errorf("collection was loaded %d times, want once", calls)
+	}
+}
+
+func TestListCacheInvalidate(t *testing.T) {
+	c := newListCache()
+	calls := 0
+	load := countingLoader(&calls, 1)
+
+	c.Get(context.Background(), "things", 1, load)
+	c.Invalidate("things")
+	if _, ok, _ := c.Get(context.Background(), "things", 1, load); !ok {
+		t.Errorf("item missing after collection was reloaded")
+	}
+	if calls != 2 {
+		t.Errorf("collection was loaded %d times, want twice", calls)
+	}
+}
+
+func TestListCacheDoesNotKeepFailedLoads(t *testing.T) {
+	c := newListCache()
+	failure := errors.New("listing failed")
+	failing := func(ctx context.Context, add func(int, interface{})) error {
+		add(1, 1)
+		return failure
+	}
+
+	if _, _, err := c.Get(context.Background(), "things", 1, failing); err != failure {
+		t.Fatalf("got %v, want the loader's error", err)
+	}
+
+	calls := 0
+	if _, ok, _ := c.Get(context.Background(), "things", 1, countingLoader(&calls, 1)); !ok || calls != 1 {
+		t.Errorf("collection wasn't loaded again after a failure")
+	}
+}
+
+func TestClientServesReadsFromList(t *testing.T) {
+	requests := map[string]int{}
+	var mu sync.Mutex
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		if r.URL.Path != "/users.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte("[]"))
+			return
+		}
+		w.Write([]byte(`[{"user": {"id": 1, "login": "alice"}}, {"user": {"id": 2, "login": "bob"}}]`))
+	})
+	client := newTestClient(t, ClientConfig{CacheListReads: true}, handler)
+
+	for _, id := range []int{1, 2} {
+		user, err := client.Users.Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != id {
+			t.Errorf("Get(%d) returned user %d", id, user.ID)
+		}
+	}
+
+	if len(requests) != 1 || requests["/users.json"] != 2 {
+		t.Errorf("made requests %v, want only the two pages of the user list", requests)
+	}
+}
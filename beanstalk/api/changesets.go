@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ChangesetsService provides access to the history of commits made to
+// repositories.
+type ChangesetsService struct {
+	client *Client
+}
+
+// ChangesetFilter selects which changesets Each will visit.
+// The zero value selects all changesets across all repositories.
+type ChangesetFilter struct {
+	// RepositoryID limits the results to a single repository. Branch
+	// may be used only when this is set.
+	RepositoryID int
+	Branch       string
+
+	// Since and Until limit the results to changesets committed within
+	// a time range. Either may be left as the zero time for an open range.
+	Since time.Time
+	Until time.Time
+}
+
+// Each calls fn for each changeset matching the given filter, newest
+// first, fetching further pages from Beanstalk only as needed. Iteration
+// stops early if fn returns false or an error.
+func (s *ChangesetsService) Each(ctx context.Context, filter *ChangesetFilter, fn func(*Changeset) (bool, error)) error {
+	pathParts := []string{"changesets"}
+	queryArgs := map[string]string{}
+	if filter.RepositoryID != 0 {
+		pathParts = []string{"changesets", "repository"}
+		queryArgs["repository_id"] = strconv.Itoa(filter.RepositoryID)
+		if filter.Branch != "" {
+			queryArgs["branch"] = filter.Branch
+		}
+	}
+
+	var res []ChangesetWrap
+	return s.client.GetPages(ctx, pathParts, queryArgs, &res, func() (bool, error) {
+		for i := range res {
+			changeset := &res[i].Changeset
+			t := changeset.Time()
+
+			if t.IsZero() && (!filter.Since.IsZero() || !filter.Until.IsZero()) {
+				// A changeset whose time can't be read can't be placed
+				// in the window, but it doesn't mean that the window
+				// has ended, so it's skipped.
+				continue
+			}
+			if !filter.Until.IsZero() && t.After(filter.Until) {
+				continue
+			}
+			if !filter.Since.IsZero() && t.Before(filter.Since) {
+				// Everything after this point is older still.
+				return false, nil
+			}
+
+			more, err := fn(changeset)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+type Changeset struct {
+	RepositoryID      int               `json:"repository_id"`
+	Revision          string            `json:"revision"`
+	HashID            string            `json:"hash_id"`
+	Author            string            `json:"author"`
+	Email             string            `json:"email"`
+	UserID            int               `json:"user_id"`
+	Message           string            `json:"message"`
+	RawTime           string            `json:"time"`
+	ChangedFiles      []json.RawMessage `json:"changed_files"`
+	ChangedDirs       []json.RawMessage `json:"changed_dirs"`
+	ChangedProperties []json.RawMessage `json:"changed_properties"`
+	TooLarge          bool              `json:"too_large"`
+}
+
+// Time returns the time at which the changeset was committed, or the zero
+// time if Beanstalk returned something unparseable.
+func (c *Changeset) Time() time.Time {
+	t, err := ParseTime(c.RawTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Beanstalk calls changesets "revision caches" in its API payloads.
+type ChangesetWrap struct {
+	Changeset Changeset `json:"revision_cache"`
+}
@@ -0,0 +1,445 @@
+// Package api is a client for the Beanstalk API. It has no dependency on
+// Terraform, so it can be used from any Go program that needs to manage
+// a Beanstalk account.
+//
+// The API is divided into services, each covering one area of Beanstalk
+// and available as a field of Client:
+//
+//	client, err := api.NewClient(&api.ClientConfig{...})
+//	repo, err := client.Repositories.Get(ctx, 123)
+//
+// The lower-level Get, Post, Put and Delete methods remain available for
+// any parts of the API that the services don't cover.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ClientConfig struct {
+	AccountName string
+	Username    string
+	AccessToken string
+
+	// BaseURL overrides the URL of the API, which is otherwise derived
+	// from AccountName. This is for testing against a fake API.
+	BaseURL string
+
+	// Timeout limits the duration of each individual HTTP request.
+	// If zero, DefaultTimeout is used.
+	Timeout time.Duration
+
+	// Every request is logged with its method, path, status and latency.
+	// If LogBodies is set then request and response headers and bodies
+	// are logged too, with the values of sensitive fields masked.
+	// SensitiveFields names payload fields to mask in addition to the
+	// built-in set, which covers passwords, tokens and email addresses.
+	LogBodies       bool
+	SensitiveFields []string
+
+	// MaxConcurrentRequests limits how many requests may be in flight at
+	// once, and MaxRequestsPerSecond limits how quickly new requests may
+	// be sent. Requests beyond these limits wait their turn. Zero means
+	// no limit.
+	MaxConcurrentRequests int
+	MaxRequestsPerSecond  float64
+
+	// CacheListReads enables a cache that serves reads of individual
+	// repositories, users and teams from a single listing of each
+	// collection, which is much faster when reading many of them. Items
+	// are dropped from the cache when written through this client, but
+	// changes made elsewhere will not be seen, so the cache should be
+	// enabled only for short-lived clients.
+	CacheListReads bool
+
+	// ConditionalRequests enables a cache of GET responses, which are
+	// then requested again conditionally so that Beanstalk can answer
+	// with 304 Not Modified if nothing has changed. Pages of paginated
+	// collections aren't cached. The cache is never emptied, so it too
+	// should be enabled only for short-lived clients.
+	ConditionalRequests bool
+}
+
+// DefaultTimeout is the request timeout used when none is given in the
+// ClientConfig.
+const DefaultTimeout = 60 * time.Second
+
+type Client struct {
+	httpClient  *http.Client
+	apiURL      *url.URL
+	username    string
+	accessToken string
+	logBodies   bool
+	redactor    *redactor
+	throttle    *throttle
+	cache       *listCache
+	responses   *responseCache
+
+	Account            *AccountService
+	Repositories       *RepositoriesService
+	Users              *UsersService
+	Invitations        *InvitationsService
+	Teams              *TeamsService
+	Integrations       *IntegrationsService
+	CodeReviews        *CodeReviewsService
+	Branches           *BranchesService
+	BranchRestrictions *BranchRestrictionsService
+	Tags               *TagsService
+	Releases           *ReleasesService
+	ServerEnvironments *ServerEnvironmentsService
+	Changesets         *ChangesetsService
+	Permissions        *PermissionsService
+}
+
+func NewClient(config *ClientConfig) (*Client, error) {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s.beanstalkapp.com/api/", config.AccountName)
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		// Paths are resolved relative to the base URL, which only works
+		// as intended if it's a "directory".
+		baseURL += "/"
+	}
+	apiURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		httpClient:  httpClient,
+		apiURL:      apiURL,
+		username:    config.Username,
+		accessToken: config.AccessToken,
+		logBodies:   config.LogBodies,
+		redactor:    newRedactor(config.SensitiveFields),
+		throttle:    newThrottle(config.MaxConcurrentRequests, config.MaxRequestsPerSecond),
+	}
+
+	if config.CacheListReads {
+		c.cache = newListCache()
+	}
+	if config.ConditionalRequests {
+		c.responses = newResponseCache()
+	}
+
+	c.Account = &AccountService{client: c}
+	c.Repositories = &RepositoriesService{client: c}
+	c.Users = &UsersService{client: c}
+	c.Invitations = &InvitationsService{client: c}
+	c.Teams = &TeamsService{client: c}
+	c.Integrations = &IntegrationsService{client: c}
+	c.CodeReviews = &CodeReviewsService{client: c}
+	c.Branches = &BranchesService{client: c}
+	c.BranchRestrictions = &BranchRestrictionsService{client: c}
+	c.Tags = &TagsService{client: c}
+	c.Releases = &ReleasesService{client: c}
+	c.ServerEnvironments = &ServerEnvironmentsService{client: c}
+	c.Changesets = &ChangesetsService{client: c}
+	c.Permissions = &PermissionsService{client: c}
+
+	return c, nil
+}
+
+type request struct {
+	Method    string
+	PathParts []string
+	QueryArgs map[string]string
+	Headers   map[string]string
+	BodyBytes []byte
+}
+
+func (c *Client) rawRequest(ctx context.Context, req *request) ([]byte, error) {
+	httpReq, err := req.MakeHTTPRequest(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	var cached *cachedResponse
+	if c.responses != nil {
+		cached = c.responses.Prepare(httpReq)
+	}
+	if c.logBodies {
+		log.Printf("Beanstalk request headers are %v", c.redactor.Headers(httpReq.Header))
+		log.Printf("Beanstalk request body is %v", c.redactor.Body(req.BodyBytes))
+	}
+
+	release, err := c.throttle.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		log.Printf("Beanstalk %v %v failed after %v: %s", httpReq.Method, httpReq.URL.Path, time.Since(start), err)
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, err := ioutil.ReadAll(res.Body)
+	log.Printf("Beanstalk %v %v returned %v in %v", httpReq.Method, httpReq.URL.Path, res.StatusCode, time.Since(start))
+	if c.logBodies {
+		log.Printf("Beanstalk response headers are %v", c.redactor.Headers(res.Header))
+		log.Printf("Beanstalk response body is %v", c.redactor.Body(resBodyBytes))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		log.Printf("Beanstalk %v %v is unchanged; using cached response", httpReq.Method, httpReq.URL.Path)
+		return cached.body, nil
+	}
+
+	if res.StatusCode == 404 {
+		return nil, &NotFoundError{}
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP Error %v", res.StatusCode)
+	}
+
+	if c.responses != nil {
+		c.responses.Store(httpReq, res, resBodyBytes)
+	}
+
+	// Any successful status is accepted, with whatever body came with it.
+	// 204 No Content and friends produce an empty body, and it's up to
+	// the caller to decide whether that is acceptable.
+	return resBodyBytes, nil
+}
+
+// jsonRequest makes a request whose response must contain a JSON payload
+// if result is non-nil.
+func (c *Client) jsonRequest(ctx context.Context, method string, pathParts []string, queryArgs map[string]string, reqBody interface{}, result interface{}) error {
+	present, err := c.optionalJSONRequest(ctx, method, pathParts, queryArgs, reqBody, result)
+	if err != nil {
+		return err
+	}
+
+	if result != nil && !present {
+		return fmt.Errorf("server did not return a JSON payload")
+	}
+
+	return nil
+}
+
+// optionalJSONRequest makes a request whose response may or may not
+// contain a JSON payload. If it does, and result is non-nil, the payload
+// is decoded into result. The returned bool is true if a payload was
+// present.
+func (c *Client) optionalJSONRequest(ctx context.Context, method string, pathParts []string, queryArgs map[string]string, reqBody interface{}, result interface{}) (bool, error) {
+
+	var err error
+	var reqBodyBytes []byte
+	reqBodyBytes = nil
+	if reqBody != nil {
+		reqBodyBytes, err = json.Marshal(reqBody)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	req := &request{
+		Method:    method,
+		PathParts: pathParts,
+		QueryArgs: queryArgs,
+		BodyBytes: reqBodyBytes,
+		Headers:   map[string]string{},
+	}
+
+	if reqBody != nil {
+		req.Headers["Content-Type"] = "application/json"
+	}
+
+	resBodyBytes, err := c.rawRequest(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	if len(bytes.TrimSpace(resBodyBytes)) == 0 {
+		return false, nil
+	}
+
+	if result != nil {
+		err = json.Unmarshal(resBodyBytes, result)
+		if err != nil {
+			return true, fmt.Errorf("error decoding response JSON payload: %s", err.Error())
+		}
+	}
+
+	return true, nil
+}
+
+func (c *Client) Get(ctx context.Context, pathParts []string, queryArgs map[string]string, result interface{}) error {
+	return c.jsonRequest(ctx, "GET", pathParts, queryArgs, nil, result)
+}
+
+// PageSize is the number of items requested per page from paginated
+// collections. Some collections, such as changesets, return fewer items
+// per page than requested.
+const PageSize = 50
+
+// GetPages retrieves a paginated collection one page at a time. Each page
+// is decoded into result, which must be a pointer to a slice, and then fn
+// is called to consume it. Paging stops once a page comes back empty, or
+// as soon as fn returns false or an error. A short page doesn't mean the
+// end, since Beanstalk caps the page size of some collections below the
+// size that was asked for. Paging also stops if a page starts with the
+// same item as the page before it, since that means the collection
+// doesn't paginate and would otherwise be fetched again forever.
+func (c *Client) GetPages(ctx context.Context, pathParts []string, queryArgs map[string]string, result interface{}, fn func() (bool, error)) error {
+	resultValue := reflect.ValueOf(result).Elem()
+
+	pageArgs := map[string]string{}
+	for k, v := range queryArgs {
+		pageArgs[k] = v
+	}
+	pageArgs["per_page"] = strconv.Itoa(PageSize)
+
+	var prevFirst interface{}
+	for page := 1; ; page++ {
+		pageArgs["page"] = strconv.Itoa(page)
+
+		resultValue.Set(reflect.Zero(resultValue.Type()))
+		err := c.Get(ctx, pathParts, pageArgs, result)
+		if err != nil {
+			return err
+		}
+
+		count := resultValue.Len()
+		if count == 0 {
+			return nil
+		}
+		first := resultValue.Index(0).Interface()
+		if page > 1 && reflect.DeepEqual(first, prevFirst) {
+			return nil
+		}
+		prevFirst = first
+
+		more, err := fn()
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func (c *Client) Post(ctx context.Context, pathParts []string, reqBody interface{}, result interface{}) error {
+	return c.jsonRequest(ctx, "POST", pathParts, nil, reqBody, result)
+}
+
+func (c *Client) Put(ctx context.Context, pathParts []string, reqBody interface{}, result interface{}) error {
+	return c.jsonRequest(ctx, "PUT", pathParts, nil, reqBody, result)
+}
+
+// PostOptional is like Post, but accepts a successful response that has
+// no payload. It returns true if a payload was present and decoded into
+// result.
+func (c *Client) PostOptional(ctx context.Context, pathParts []string, reqBody interface{}, result interface{}) (bool, error) {
+	return c.optionalJSONRequest(ctx, "POST", pathParts, nil, reqBody, result)
+}
+
+// PutOptional is like Put, but accepts a successful response that has
+// no payload. It returns true if a payload was present and decoded into
+// result.
+func (c *Client) PutOptional(ctx context.Context, pathParts []string, reqBody interface{}, result interface{}) (bool, error) {
+	return c.optionalJSONRequest(ctx, "PUT", pathParts, nil, reqBody, result)
+}
+
+func (c *Client) Delete(ctx context.Context, pathParts []string) error {
+	return c.jsonRequest(ctx, "DELETE", pathParts, nil, nil, nil)
+}
+
+// WebURL returns the URL of a page in the Beanstalk web UI for the
+// account that the client is configured for.
+func (c *Client) WebURL(pathParts ...string) string {
+	escapedParts := make([]string, len(pathParts))
+	for i, part := range pathParts {
+		escapedParts[i] = url.PathEscape(part)
+	}
+	webURL := &url.URL{
+		Scheme: c.apiURL.Scheme,
+		Host:   c.apiURL.Host,
+		Path:   "/" + strings.Join(pathParts, "/"),
+	}
+	webURL.RawPath = "/" + strings.Join(escapedParts, "/")
+	return webURL.String()
+}
+
+func (r *request) MakeHTTPRequest(ctx context.Context, client *Client) (*http.Request, error) {
+	// Path parts may themselves contain slashes (branch names, for example)
+	// so each one is escaped individually.
+	escapedParts := make([]string, len(r.PathParts))
+	for i, part := range r.PathParts {
+		escapedParts[i] = url.PathEscape(part)
+	}
+	urlPath := &url.URL{
+		Path:    strings.Join(r.PathParts, "/") + ".json",
+		RawPath: strings.Join(escapedParts, "/") + ".json",
+	}
+	reqURL := client.apiURL.ResolveReference(urlPath)
+
+	if len(r.QueryArgs) > 0 {
+		urlQuery := url.Values{}
+		for k, v := range r.QueryArgs {
+			urlQuery.Add(k, v)
+		}
+		reqURL.RawQuery = urlQuery.Encode()
+	}
+
+	var body io.Reader
+	if r.BodyBytes != nil {
+		body = bytes.NewReader(r.BodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, reqURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Terraform-Beanstalk")
+	req.SetBasicAuth(client.username, client.accessToken)
+
+	for k, v := range r.Headers {
+		req.Header.Add(k, v)
+	}
+
+	return req, nil
+}
+
+type NotFoundError struct{}
+
+func (err NotFoundError) Error() string {
+	return "not found"
+}
+
+// IsNotFound returns true if the given error indicates that the requested
+// object does not exist in Beanstalk.
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
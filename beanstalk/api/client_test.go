@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newTestClient starts a server with the given handler and returns a client
+// that is configured to use it. The server is stopped when the test
+// finishes.
+func newTestClient(t *testing.T, config ClientConfig, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config.AccountName = "example"
+	config.BaseURL = server.URL
+	client, err := NewClient(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// pagedTags serves a collection of count tags named by number, returning
+// at most pageSize of them per page regardless of what was asked for. If
+// pageSize is zero then the whole collection is returned for every page,
+// as if the endpoint didn't paginate. It records the pages requested.
+type pagedTags struct {
+	count    int
+	pageSize int
+
+	mu    sync.Mutex
+	pages []int
+}
+
+func (p *pagedTags) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	p.mu.Lock()
+	p.pages = append(p.pages, page)
+	p.mu.Unlock()
+
+	start, end := 0, p.count
+	if p.pageSize > 0 {
+		start = (page - 1) * p.pageSize
+		end = start + p.pageSize
+	}
+	if start > p.count {
+		start = p.count
+	}
+	if end > p.count {
+		end = p.count
+	}
+
+	items := []TagWrap{}
+	for i := start; i < end; i++ {
+		items = append(items, TagWrap{Tag: Tag{Name: strconv.Itoa(i)}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+func eachTagName(t *testing.T, client *Client) []string {
+	names := []string{}
+	err := client.Tags.Each(context.Background(), 1, func(tag *Tag) (bool, error) {
+		names = append(names, tag.Name)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestGetPagesContinuesPastShortPages(t *testing.T) {
+	// Beanstalk caps some collections below the requested page size, so
+	// a short page isn't the last one.
+	tags := &pagedTags{count: 45, pageSize: 20}
+	client := newTestClient(t, ClientConfig{}, tags)
+
+	names := eachTagName(t, client)
+	if len(names) != 45 {
+		t.Errorf("got %d tags, want 45", len(names))
+	}
+	if len(tags.pages) != 4 {
+		t.Errorf("requested pages %v, want 1 to 4", tags.pages)
+	}
+}
+
+func TestGetPagesStopsAtEmptyPage(t *testing.T) {
+	tags := &pagedTags{count: PageSize, pageSize: PageSize}
+	client := newTestClient(t, ClientConfig{}, tags)
+
+	names := eachTagName(t, client)
+	if len(names) != PageSize {
+		t.Errorf("got %d tags, want %d", len(names), PageSize)
+	}
+	if len(tags.pages) != 2 {
+		t.Errorf("requested pages %v, want 1 and 2", tags.pages)
+	}
+}
+
+func TestGetPagesStopsAtRepeatedPage(t *testing.T) {
+	tags := &pagedTags{count: 3}
+	client := newTestClient(t, ClientConfig{}, tags)
+
+	names := eachTagName(t, client)
+	if len(names) != 3 {
+		t.Errorf("got tags %v, want each of 3 tags once", names)
+	}
+	if len(tags.pages) != 2 {
+		t.Errorf("requested pages %v, want 1 and 2", tags.pages)
+	}
+
+	_, err := client.Tags.Get(context.Background(), 1, "missing")
+	if !IsNotFound(err) {
+		t.Errorf("got %v for missing tag, want not found", err)
+	}
+}
+
+func TestGetPagesStopsWhenAsked(t *testing.T) {
+	tags := &pagedTags{count: 45, pageSize: 20}
+	client := newTestClient(t, ClientConfig{}, tags)
+
+	tag, err := client.Tags.Get(context.Background(), 1, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Name != "5" {
+		t.Errorf("got tag %q, want 5", tag.Name)
+	}
+	if len(tags.pages) != 1 {
+		t.Errorf("requested pages %v, want only 1", tags.pages)
+	}
+}
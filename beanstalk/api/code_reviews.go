@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// CodeReviewsService manages the code review features of repositories.
+type CodeReviewsService struct {
+	client *Client
+}
+
+func (s *CodeReviewsService) GetSettings(ctx context.Context, repositoryId int) (*CodeReviewSettingsRead, error) {
+	res := &CodeReviewSettingsRead{}
+
+	err := s.client.Get(ctx, []string{strconv.Itoa(repositoryId), "code_reviews", "settings"}, nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *CodeReviewsService) UpdateSettings(ctx context.Context, repositoryId int, settings *CodeReviewSettingsWrite) error {
+	return s.client.Put(ctx, []string{strconv.Itoa(repositoryId), "code_reviews", "settings"}, settings, nil)
+}
+
+// As with teams, Beanstalk uses a different representation of the code
+// review settings for reading than for writing.
+
+type CodeReviewSettingsRead struct {
+	UnanimousApproval bool                `json:"unanimous_approval"`
+	AutoReopen        bool                `json:"auto_reopen"`
+	DefaultWatchers   []CodeReviewWatcher `json:"default_watchers"`
+	DefaultAssignees  []CodeReviewWatcher `json:"default_assignees"`
+}
+
+type CodeReviewSettingsWrite struct {
+	UnanimousApproval      bool  `json:"unanimous_approval"`
+	AutoReopen             bool  `json:"auto_reopen"`
+	DefaultAssigneeUserIDs []int `json:"default_assignees"`
+	DefaultWatcherUserIDs  []int `json:"default_watchers_user_ids"`
+	DefaultWatcherTeamIDs  []int `json:"default_watchers_team_ids"`
+}
+
+type CodeReviewWatcher struct {
+	ID       int    `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Username string `json:"login"`
+	Email    string `json:"email"`
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+)
+
+// responseCache remembers the validators and body of each successful GET
+// response, so that the same resource can later be requested
+// conditionally. Beanstalk answers a conditional request with 304 Not
+// Modified if nothing has changed, which saves transferring and encoding
+// the body again.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{
+		entries: map[string]*cachedResponse{},
+	}
+}
+
+// Prepare adds conditional headers to the given GET request if a
+// response for the same URL has been cached, and returns that response.
+// It returns nil if the request can't be made conditional.
+func (c *responseCache) Prepare(req *http.Request) *cachedResponse {
+	if req.Method != "GET" {
+		return nil
+	}
+
+	c.mu.Lock()
+	entry := c.entries[req.URL.String()]
+	c.mu.Unlock()
+
+	if entry == nil {
+		return nil
+	}
+
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+	return entry
+}
+
+// Store remembers a successful GET response, if Beanstalk returned any
+// validators with it. Pages of paginated collections are skipped, since
+// they're typically streamed through once and would otherwise keep a copy
+// of the whole collection.
+func (c *responseCache) Store(req *http.Request, res *http.Response, body []byte) {
+	if req.Method != "GET" || res.StatusCode != http.StatusOK {
+		return
+	}
+	if req.URL.Query().Get("page") != "" {
+		return
+	}
+
+	entry := &cachedResponse{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+		body:         body,
+	}
+	if entry.etag == "" && entry.lastModified == "" {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[req.URL.String()] = entry
+	c.mu.Unlock()
+}
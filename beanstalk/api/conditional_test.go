@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// etagServer serves a single repository with an ETag, answering 304 Not
+// Modified to requests that already have it. It records the If-None-Match
+// header of each request.
+type etagServer struct {
+	mu          sync.Mutex
+	ifNoneMatch []string
+}
+
+func (s *etagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ifNoneMatch = append(s.ifNoneMatch, r.Header.Get("If-None-Match"))
+	s.mu.Unlock()
+
+	if r.URL.Query().Get("page") == "2" {
+		w.Write([]byte("[]"))
+		return
+	}
+
+	w.Header().Set("ETag", `"v1"`)
+	if r.Header.Get("If-None-Match") == `"v1"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	switch r.URL.Path {
+	case "/repositories/1.json":
+		w.Write([]byte(`{"repository": {"id": 1, "name": "example"}}`))
+	case "/repositories.json":
+		w.Write([]byte(`[{"repository": {"id": 1, "name": "example"}}]`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestConditionalRequestsReuseBody(t *testing.T) {
+	server := &etagServer{}
+	client := newTestClient(t, ClientConfig{ConditionalRequests: true}, server)
+
+	for i := 0; i < 2; i++ {
+		repo, err := client.Repositories.Get(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repo.Name != "example" {
+			t.Errorf("read %d returned repository %q, want example", i, repo.Name)
+		}
+	}
+
+	if len(server.ifNoneMatch) != 2 || server.ifNoneMatch[0] != "" || server.ifNoneMatch[1] != `"v1"` {
+		t.Errorf("sent If-None-Match headers %q, want none and then the ETag", server.ifNoneMatch)
+	}
+}
+
+func TestConditionalRequestsDisabledByDefault(t *testing.T) {
+	server := &etagServer{}
+	client := newTestClient(t, ClientConfig{}, server)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Repositories.Get(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, header := range server.ifNoneMatch {
+		if header != "" {
+			t.Errorf("sent If-None-Match %q with conditional requests disabled", header)
+		}
+	}
+}
+
+func TestConditionalRequestsSkipPages(t *testing.T) {
+	server := &etagServer{}
+	client := newTestClient(t, ClientConfig{ConditionalRequests: true}, server)
+
+	for i := 0; i < 2; i++ {
+		count := 0
+		err := client.Repositories.Each(context.Background(), func(repo *Repository) (bool, error) {
+			count++
+			return true, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("listing %d returned %d repositories, want 1", i, count)
+		}
+	}
+
+	for _, header := range server.ifNoneMatch {
+		if header != "" {
+			t.Errorf("sent If-None-Match %q for a page of a collection", header)
+		}
+	}
+	if len(client.responses.entries) != 0 {
+		t.Errorf("cached %d pages of a collection, want none", len(client.responses.entries))
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// IntegrationsService manages the integrations between repositories and
+// other services. Beanstalk has many integration types, each with its own
+// set of attributes, so integrations are represented as generic maps of
+// attributes rather than as structs.
+type IntegrationsService struct {
+	client *Client
+}
+
+// Get returns the attributes of an integration.
+func (s *IntegrationsService) Get(ctx context.Context, repositoryName string, id int) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+
+	err := s.client.Get(ctx, []string{"repositories", repositoryName, "integrations", strconv.Itoa(id)}, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	integration, ok := data["integration"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("server did not return an integration")
+	}
+	return integration, nil
+}
+
+// Each calls fn with the attributes of each integration on a repository,
+// stopping early if fn returns false or an error.
+func (s *IntegrationsService) Each(ctx context.Context, repositoryName string, fn func(map[string]interface{}) (bool, error)) error {
+	var res []map[string]map[string]interface{}
+	return s.client.GetPages(ctx, []string{"repositories", repositoryName, "integrations"}, nil, &res, func() (bool, error) {
+		for _, item := range res {
+			more, err := fn(item["integration"])
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Create creates an integration of the given type, returning its id.
+func (s *IntegrationsService) Create(ctx context.Context, repositoryName string, integrationType string, attrs map[string]interface{}) (int, error) {
+	type responseIntegration struct {
+		Id int `json:"id"`
+	}
+	type response struct {
+		Integration responseIntegration `json:"integration"`
+	}
+
+	res := &response{}
+
+	err := s.client.Post(ctx, []string{"repositories", repositoryName, "integrations"}, integrationRequest(integrationType, attrs), res)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Integration.Id, nil
+}
+
+// Update changes the given attributes of an integration, leaving any
+// others untouched.
+func (s *IntegrationsService) Update(ctx context.Context, repositoryName string, id int, integrationType string, attrs map[string]interface{}) error {
+	return s.client.Put(ctx, []string{"repositories", repositoryName, "integrations", strconv.Itoa(id)}, integrationRequest(integrationType, attrs), nil)
+}
+
+func (s *IntegrationsService) Delete(ctx context.Context, repositoryName string, id int) error {
+	return s.client.Delete(ctx, []string{"repositories", repositoryName, "integrations", strconv.Itoa(id)})
+}
+
+func integrationRequest(integrationType string, attrs map[string]interface{}) map[string]interface{} {
+	integration := map[string]interface{}{}
+	for k, v := range attrs {
+		integration[k] = v
+	}
+	integration["type"] = integrationType
+
+	return map[string]interface{}{
+		"integration": integration,
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// InvitationsService invites new users to an account.
+type InvitationsService struct {
+	client *Client
+}
+
+// Create invites a new user, which also creates the user in Beanstalk.
+// Only the name and email of the given user are used.
+func (s *InvitationsService) Create(ctx context.Context, user *User) (*Invitation, error) {
+	req := &InvitationCreateRequestWrap{
+		InvitationCreateRequest{
+			User{
+				Name:  user.Name,
+				Email: user.Email,
+			},
+		},
+	}
+	res := &InvitationWrap{}
+
+	err := s.client.Post(ctx, []string{"invitations"}, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Invitation, nil
+}
+
+// Get returns a pending invitation. Beanstalk discards invitations once
+// they are accepted, so NotFoundError means that the invitation has been
+// accepted (or that its user was deleted).
+func (s *InvitationsService) Get(ctx context.Context, id int) (*Invitation, error) {
+	res := &InvitationWrap{}
+
+	err := s.client.Get(ctx, []string{"invitations", strconv.Itoa(id)}, nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Invitation, nil
+}
+
+// Resend sends the invitation email for a user again.
+func (s *InvitationsService) Resend(ctx context.Context, userId int) error {
+	return s.client.Put(ctx, []string{"invitations", "resend", strconv.Itoa(userId)}, nil, nil)
+}
+
+type Invitation struct {
+	ID        int    `json:"id,omitempty"`
+	UserID    int    `json:"user_id,omitempty"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type InvitationWrap struct {
+	Invitation Invitation `json:"invitation"`
+}
+
+type InvitationCreateRequest struct {
+	User User `json:"user"`
+}
+
+type InvitationCreateRequestWrap struct {
+	Invitation InvitationCreateRequest `json:"invitation"`
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// PermissionsService reports the repository permissions granted directly
+// to individual users, as opposed to those granted via teams.
+type PermissionsService struct {
+	client *Client
+}
+
+// List returns the permissions granted directly to the given user.
+// Account owners and admins have access to every repository without
+// any explicit permissions, so the list is empty for them.
+func (s *PermissionsService) List(ctx context.Context, userId int) ([]Permission, error) {
+	var res []PermissionWrap
+	err := s.client.Get(ctx, []string{"permissions", strconv.Itoa(userId)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]Permission, len(res))
+	for i, item := range res {
+		permissions[i] = item.Permission
+	}
+	return permissions, nil
+}
+
+// Delete revokes a permission.
+func (s *PermissionsService) Delete(ctx context.Context, id int) error {
+	return s.client.Delete(ctx, []string{"permissions", strconv.Itoa(id)})
+}
+
+// Permission grants a user access to a repository. A user may have
+// more than one permission for a repository when they can deploy to
+// only some of its server environments, in which case each permission
+// gives one of those environments.
+type Permission struct {
+	ID                    int  `json:"id,omitempty"`
+	UserID                int  `json:"user_id"`
+	RepositoryID          int  `json:"repository_id"`
+	CanWrite              bool `json:"write"`
+	FullDeploymentsAccess bool `json:"full_deployments_access"`
+	ServerEnvironmentID   int  `json:"server_environment_id,omitempty"`
+}
+
+type PermissionWrap struct {
+	Permission Permission `json:"permission"`
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const redactedValue = "REDACTED"
+
+// defaultSensitiveFields are the payload fields that are always masked in
+// logs. Any field whose name contains "password", "token" or "secret" is
+// masked too.
+var defaultSensitiveFields = []string{
+	"email",
+}
+
+// sensitiveHeaders are the request and response headers whose values are
+// always masked in logs.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// redactor masks sensitive values in request and response data so that it
+// can be safely written to debug logs.
+type redactor struct {
+	fields map[string]bool
+}
+
+func newRedactor(extraFields []string) *redactor {
+	r := &redactor{
+		fields: map[string]bool{},
+	}
+	for _, field := range defaultSensitiveFields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	for _, field := range extraFields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	return r
+}
+
+func (r *redactor) isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	if r.fields[field] {
+		return true
+	}
+	return strings.Contains(field, "password") ||
+		strings.Contains(field, "token") ||
+		strings.Contains(field, "secret")
+}
+
+// Body returns a loggable version of a JSON payload with all of its
+// sensitive fields masked, at any depth. Payloads that aren't valid JSON
+// can't be safely inspected, so only their size is reported.
+func (r *redactor) Body(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("(%d bytes of non-JSON data)", len(body))
+	}
+
+	redacted, err := json.Marshal(r.value(data))
+	if err != nil {
+		return fmt.Sprintf("(%d bytes of unloggable data)", len(body))
+	}
+	return string(redacted)
+}
+
+func (r *redactor) value(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			if r.isSensitive(k) && item != nil {
+				ret[k] = redactedValue
+			} else {
+				ret[k] = r.value(item)
+			}
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = r.value(item)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+// Headers returns a copy of the given headers with the values of
+// sensitive headers masked.
+func (r *redactor) Headers(headers http.Header) http.Header {
+	ret := make(http.Header, len(headers))
+	for k, v := range headers {
+		ret[k] = v
+	}
+	for _, k := range sensitiveHeaders {
+		if _, ok := ret[k]; ok {
+			ret[k] = []string{redactedValue}
+		}
+	}
+	return ret
+}
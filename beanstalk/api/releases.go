@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// ReleasesService manages releases, which are deployments of a repository
+// to one of its server environments.
+type ReleasesService struct {
+	client *Client
+}
+
+func (s *ReleasesService) Get(ctx context.Context, repositoryId int, id int) (*Release, error) {
+	var res ReleaseWrap
+	err := s.client.Get(ctx, []string{strconv.Itoa(repositoryId), "releases", strconv.Itoa(id)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Release, nil
+}
+
+// Each calls fn for each release of a repository, newest first, stopping
+// early if fn returns false or an error. If environmentId is non-zero then
+// only releases to that environment are visited.
+func (s *ReleasesService) Each(ctx context.Context, repositoryId int, environmentId int, fn func(*Release) (bool, error)) error {
+	queryArgs := map[string]string{}
+	if environmentId != 0 {
+		queryArgs["environment_id"] = strconv.Itoa(environmentId)
+	}
+
+	var res []ReleaseWrap
+	return s.client.GetPages(ctx, []string{strconv.Itoa(repositoryId), "releases"}, queryArgs, &res, func() (bool, error) {
+		for i := range res {
+			more, err := fn(&res[i].Release)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Create starts a deployment to the given environment. Only the release's
+// Revision, Comment and DeployFromScratch fields are used. Deployment
+// happens asynchronously, so the returned release will usually still be
+// waiting or pending.
+func (s *ReleasesService) Create(ctx context.Context, repositoryId int, environmentId int, release *Release) (*Release, error) {
+	req := &ReleaseWrap{
+		Release: Release{
+			Revision:          release.Revision,
+			Comment:           release.Comment,
+			DeployFromScratch: release.DeployFromScratch,
+		},
+	}
+	res := &ReleaseWrap{}
+
+	// The target environment is given in the query string rather than
+	// in the request body.
+	queryArgs := map[string]string{
+		"environment_id": strconv.Itoa(environmentId),
+	}
+	err := s.client.jsonRequest(ctx, "POST", []string{strconv.Itoa(repositoryId), "releases"}, queryArgs, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Release, nil
+}
+
+type Release struct {
+	ID                  int    `json:"id,omitempty"`
+	RepositoryID        int    `json:"repository_id,omitempty"`
+	EnvironmentID       int    `json:"environment_id,omitempty"`
+	EnvironmentName     string `json:"environment_name,omitempty"`
+	Revision            string `json:"revision"`
+	EnvironmentRevision string `json:"environment_revision,omitempty"`
+	Comment             string `json:"comment"`
+	DeployFromScratch   bool   `json:"deploy_from_scratch"`
+	State               string `json:"state,omitempty"`
+	Author              string `json:"author,omitempty"`
+	UserID              int    `json:"user_id,omitempty"`
+	CreatedAt           string `json:"created_at,omitempty"`
+	UpdatedAt           string `json:"updated_at,omitempty"`
+}
+
+type ReleaseWrap struct {
+	Release Release `json:"release"`
+}
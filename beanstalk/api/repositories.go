@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+// RepositoriesService manages the repositories in an account.
+type RepositoriesService struct {
+	client *Client
+}
+
+func (s *RepositoriesService) Get(ctx context.Context, id int) (*Repository, error) {
+	if s.client.cache != nil {
+		item, ok, err := s.client.cache.Get(ctx, "repositories", id, func(ctx context.Context, add func(int, interface{})) error {
+			return s.Each(ctx, func(repo *Repository) (bool, error) {
+				add(repo.ID, *repo)
+				return true, nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			repo := item.(Repository)
+			return &repo, nil
+		}
+	}
+
+	var res RepositoryWrap
+	err := s.client.Get(ctx, []string{"repositories", strconv.Itoa(id)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Repository, nil
+}
+
+// Each calls fn for each repository in the account, stopping early if fn
+// returns false or an error.
+func (s *RepositoriesService) Each(ctx context.Context, fn func(*Repository) (bool, error)) error {
+	var res []RepositoryWrap
+	return s.client.GetPages(ctx, []string{"repositories"}, nil, &res, func() (bool, error) {
+		for i := range res {
+			more, err := fn(&res[i].Repository)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Create creates a new repository. Only the title, name, type and
+// Subversion structure settings are used; other settings must be applied
+// afterwards with Update.
+func (s *RepositoriesService) Create(ctx context.Context, repo *Repository) (*Repository, error) {
+	req := &Repository{
+		Title:              repo.Title,
+		Name:               repo.Name,
+		TypeID:             repo.TypeID,
+		CreateSVNStructure: repo.CreateSVNStructure,
+	}
+	res := &RepositoryWrap{}
+
+	err := s.client.Post(ctx, []string{"repositories"}, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Repository, nil
+}
+
+// Update changes the title, color label and default branch of a
+// repository. Renaming requires the separate Rename method.
+func (s *RepositoriesService) Update(ctx context.Context, id int, repo *Repository) error {
+	req := &RepositoryWrap{
+		Repository: Repository{
+			Title:            repo.Title,
+			ColorLabel:       repo.ColorLabel,
+			DefaultGitBranch: repo.DefaultGitBranch,
+		},
+	}
+
+	s.forget(id)
+	return s.client.Put(ctx, []string{"repositories", strconv.Itoa(id)}, req, nil)
+}
+
+func (s *RepositoriesService) Rename(ctx context.Context, id int, name string) error {
+	req := &RepositoryWrap{
+		Repository: Repository{
+			Name: name,
+		},
+	}
+
+	s.forget(id)
+	return s.client.Put(ctx, []string{"repositories", strconv.Itoa(id), "rename"}, req, nil)
+}
+
+func (s *RepositoriesService) forget(id int) {
+	if s.client.cache != nil {
+		s.client.cache.Forget("repositories", id)
+	}
+}
+
+type Repository struct {
+	ID                 int                `json:"id,omitempty"`
+	Title              string             `json:"title"`
+	Name               string             `json:"name"`
+	ColorLabel         string             `json:"color_label,omitempty"`
+	DefaultGitBranch   string             `json:"default_branch,omitempty"`
+	TypeID             string             `json:"type_id,omitempty"`
+	VCS                string             `json:"vcs,omitempty"`
+	CreateSVNStructure bool               `json:"create_structure"`
+	URL                string             `json:"repository_url,omitempty"`
+	HTTPSURL           string             `json:"repository_url_https,omitempty"`
+	Type               string             `json:"type,omitempty"`
+	Revision           RepositoryRevision `json:"revision,omitempty"`
+	StorageUsedBytes   int64              `json:"storage_used_bytes,omitempty"`
+	CreatedAt          string             `json:"created_at,omitempty"`
+	UpdatedAt          string             `json:"updated_at,omitempty"`
+	LastCommitAt       string             `json:"last_commit_at,omitempty"`
+}
+
+type RepositoryWrap struct {
+	Repository Repository `json:"repository"`
+}
+
+// RepositoryRevision is the latest revision of a repository. Subversion
+// repositories report this as a number while git repositories report
+// a commit hash, so it's kept as a string in either case.
+type RepositoryRevision string
+
+func (r *RepositoryRevision) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*r = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*r = RepositoryRevision(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*r = RepositoryRevision(n.String())
+	return nil
+}
+
+// Int returns the revision as a Subversion revision number, or zero if
+// it isn't one.
+func (r RepositoryRevision) Int() int {
+	n, err := strconv.Atoi(string(r))
+	if err != nil {
+		return 0
+	}
+	return n
+}
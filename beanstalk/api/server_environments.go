@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// ServerEnvironmentsService provides access to the server environments
+// that a repository can be deployed to.
+type ServerEnvironmentsService struct {
+	client *Client
+}
+
+func (s *ServerEnvironmentsService) Get(ctx context.Context, repositoryId int, id int) (*ServerEnvironment, error) {
+	var res ServerEnvironmentWrap
+	err := s.client.Get(ctx, []string{strconv.Itoa(repositoryId), "server_environments", strconv.Itoa(id)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ServerEnvironment, nil
+}
+
+type ServerEnvironment struct {
+	ID             int    `json:"id,omitempty"`
+	RepositoryID   int    `json:"repository_id,omitempty"`
+	Name           string `json:"name"`
+	BranchName     string `json:"branch_name,omitempty"`
+	ColorLabel     string `json:"color_label,omitempty"`
+	Automatic      bool   `json:"automatic"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	CreatedAt      string `json:"created_at,omitempty"`
+	UpdatedAt      string `json:"updated_at,omitempty"`
+}
+
+type ServerEnvironmentWrap struct {
+	ServerEnvironment ServerEnvironment `json:"server_environment"`
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// TagsService manages the tags of git repositories.
+type TagsService struct {
+	client *Client
+}
+
+// Each calls fn for each tag in a repository, stopping early if fn
+// returns false or an error.
+func (s *TagsService) Each(ctx context.Context, repositoryId int, fn func(*Tag) (bool, error)) error {
+	var res []TagWrap
+	return s.client.GetPages(ctx, []string{"repositories", strconv.Itoa(repositoryId), "tags"}, nil, &res, func() (bool, error) {
+		for i := range res {
+			more, err := fn(&res[i].Tag)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Get returns the tag with the given name, or a NotFoundError if there
+// is no such tag.
+func (s *TagsService) Get(ctx context.Context, repositoryId int, name string) (*Tag, error) {
+	var found *Tag
+	err := s.Each(ctx, repositoryId, func(tag *Tag) (bool, error) {
+		if tag.Name == name {
+			t := *tag
+			found = &t
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, &NotFoundError{}
+	}
+	return found, nil
+}
+
+// Create creates an annotated tag. The tag's Ref may be a branch name or
+// a commit hash.
+func (s *TagsService) Create(ctx context.Context, repositoryId int, tag *Tag) error {
+	req := &TagWrap{
+		Tag: *tag,
+	}
+
+	return s.client.Post(ctx, []string{"repositories", strconv.Itoa(repositoryId), "tags"}, req, nil)
+}
+
+func (s *TagsService) Delete(ctx context.Context, repositoryId int, name string) error {
+	return s.client.Delete(ctx, []string{"repositories", strconv.Itoa(repositoryId), "tags", name})
+}
+
+type Tag struct {
+	Name     string `json:"name"`
+	Ref      string `json:"ref,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+type TagWrap struct {
+	Tag Tag `json:"tag"`
+}
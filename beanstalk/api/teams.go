@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// TeamsService manages the teams in an account, along with each team's
+// members and repository permissions.
+type TeamsService struct {
+	client *Client
+}
+
+func (s *TeamsService) Get(ctx context.Context, id int) (*TeamRead, error) {
+	if s.client.cache != nil {
+		item, ok, err := s.client.cache.Get(ctx, "teams", id, func(ctx context.Context, add func(int, interface{})) error {
+			return s.Each(ctx, func(team *TeamRead) (bool, error) {
+				add(team.ID, *team)
+				return true, nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			team := item.(TeamRead)
+			return &team, nil
+		}
+	}
+
+	var res TeamReadWrap
+	err := s.client.Get(ctx, []string{"teams", strconv.Itoa(id)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Team, nil
+}
+
+// Each calls fn for each team in the account, stopping early if fn
+// returns false or an error.
+func (s *TeamsService) Each(ctx context.Context, fn func(*TeamRead) (bool, error)) error {
+	var res []TeamReadWrap
+	return s.client.GetPages(ctx, []string{"teams"}, nil, &res, func() (bool, error) {
+		for i := range res {
+			more, err := fn(&res[i].Team)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+func (s *TeamsService) Create(ctx context.Context, team *TeamWrite) (*TeamRead, error) {
+	res := &TeamReadWrap{}
+
+	err := s.client.Post(ctx, []string{"teams"}, team, res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Team, nil
+}
+
+func (s *TeamsService) Update(ctx context.Context, id int, team *TeamWrite) (*TeamRead, error) {
+	s.forget(id)
+
+	res := &TeamReadWrap{}
+
+	present, err := s.client.PutOptional(ctx, []string{"teams", strconv.Itoa(id)}, team, res)
+	if err != nil {
+		return nil, err
+	}
+	if !present {
+		// Beanstalk normally returns the updated team, but if it
+		// answers with no content then we must ask for it separately.
+		return s.Get(ctx, id)
+	}
+	return &res.Team, nil
+}
+
+func (s *TeamsService) Delete(ctx context.Context, id int) error {
+	s.forget(id)
+	return s.client.Delete(ctx, []string{"teams", strconv.Itoa(id)})
+}
+
+func (s *TeamsService) forget(id int) {
+	if s.client.cache != nil {
+		s.client.cache.Forget("teams", id)
+	}
+}
+
+// Beanstalk uses a different representation of teams for reading than
+// for writing, so there are separate types for each.
+
+type TeamWrite struct {
+	ID          int                                       `json:"id,omitempty"`
+	Name        string                                    `json:"name"`
+	ColorLabel  string                                    `json:"color_label,omitempty"`
+	UserIDs     []int                                     `json:"users"`
+	Permissions map[string]TeamRepositoryPermissionsWrite `json:"permissions"`
+}
+
+type TeamRead struct {
+	ID          int                             `json:"id,omitempty"`
+	Name        string                          `json:"name"`
+	ColorLabel  string                          `json:"color_label,omitempty"`
+	Users       []User                          `json:"users"`
+	Permissions []TeamRepositoryPermissionsRead `json:"permissions"`
+}
+
+// ToWrite returns the write representation of a team, for making changes
+// to a team that was read from the API.
+func (t *TeamRead) ToWrite() *TeamWrite {
+	team := &TeamWrite{
+		ID:          t.ID,
+		Name:        t.Name,
+		ColorLabel:  t.ColorLabel,
+		UserIDs:     make([]int, len(t.Users)),
+		Permissions: map[string]TeamRepositoryPermissionsWrite{},
+	}
+	for i, user := range t.Users {
+		team.UserIDs[i] = user.ID
+	}
+	for _, permissions := range t.Permissions {
+		team.Permissions[strconv.Itoa(permissions.RepositoryID)] = TeamRepositoryPermissionsWrite{
+			CanWrite:                permissions.CanWrite,
+			CanDeploy:               permissions.CanDeploy,
+			CanConfigureDeployments: permissions.CanConfigureDeployments,
+		}
+	}
+	return team
+}
+
+type TeamReadWrap struct {
+	Team TeamRead `json:"team"`
+}
+
+type TeamRepositoryPermissionsWrite struct {
+	CanWrite                bool `json:"write"`
+	CanDeploy               bool `json:"deploy"`
+	CanConfigureDeployments bool `json:"configure_deployments"`
+}
+
+type TeamRepositoryPermissionsRead struct {
+	RepositoryID            int    `json:"repository_id"`
+	RepositoryTitle         string `json:"repository_title"`
+	CanWrite                bool   `json:"write"`
+	CanDeploy               bool   `json:"deploy"`
+	CanConfigureDeployments bool   `json:"configure_deployments"`
+}
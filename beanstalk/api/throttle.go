@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// throttle limits both the number of requests that may be in flight at
+// once and the rate at which new requests may start, using a semaphore and
+// a token bucket respectively. Either limit may be disabled.
+type throttle struct {
+	slots chan struct{}
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newThrottle creates a throttle allowing at most maxConcurrent requests
+// in flight and perSecond requests per second. A zero value disables the
+// corresponding limit.
+func newThrottle(maxConcurrent int, perSecond float64) *throttle {
+	t := &throttle{}
+
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	if perSecond > 0 {
+		t.rate = perSecond
+		t.burst = math.Max(1, math.Floor(perSecond))
+		t.tokens = t.burst
+		t.last = time.Now()
+	}
+
+	return t
+}
+
+// Acquire blocks until a request may be sent, or until the context is
+// cancelled. On success the returned function must be called once the
+// request has completed.
+func (t *throttle) Acquire(ctx context.Context) (func(), error) {
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if t.slots != nil {
+			<-t.slots
+		}
+	}
+
+	if err := t.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// wait takes a token from the bucket, sleeping until one is available.
+func (t *throttle) wait(ctx context.Context) error {
+	if t.rate == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	t.last = now
+
+	// Taking the token even if the bucket is empty reserves our place in
+	// the queue; the resulting debt is paid off by sleeping.
+	t.tokens--
+	delay := time.Duration(-t.tokens / t.rate * float64(time.Second))
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give back the token we didn't use.
+		t.mu.Lock()
+		t.tokens++
+		t.mu.Unlock()
+		return ctx.Err()
+	}
+}
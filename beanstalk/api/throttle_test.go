@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThrottleUnlimited(t *testing.T) {
+	th := newThrottle(0, 0)
+	for i := 0; i < 100; i++ {
+		release, err := th.Acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+	}
+}
+
+func TestThrottleLimitsConcurrency(t *testing.T) {
+	th := newThrottle(1, 0)
+
+	release, err := th.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := th.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("second request got %v while the first was in flight, want deadline exceeded", err)
+	}
+
+	release()
+	release, err = th.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("request after release got %v", err)
+	}
+	release()
+}
+
+func TestThrottleLimitsRate(t *testing.T) {
+	th := newThrottle(0, 10)
+
+	// The bucket starts full, so a burst of requests goes straight away.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		release, err := th.Acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("burst took %v, want no waiting", elapsed)
+	}
+
+	// The next one has to wait for a token to be added.
+	start = time.Now()
+	release, err := th.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("request after burst took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestThrottleCancelRefundsToken(t *testing.T) {
+	th := newThrottle(1, 1)
+
+	release, err := th.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := th.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("request with an empty bucket got %v, want deadline exceeded", err)
+	}
+
+	// The cancelled request gave back the token it reserved, so the
+	// bucket isn't left in debt...
+	th.mu.Lock()
+	tokens := th.tokens
+	th.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("bucket has %v tokens after cancellation, want about 0", tokens)
+	}
+
+	// ...and its slot, so that other requests aren't blocked.
+	if len(th.slots) != 0 {
+		t.Errorf("%d slots still held after cancellation, want 0", len(th.slots))
+	}
+}
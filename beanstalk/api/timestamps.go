@@ -0,0 +1,14 @@
+package api
+
+import (
+	"time"
+)
+
+// TimeLayout is the layout that Beanstalk uses for timestamps in API
+// payloads, in the form accepted by time.Parse.
+const TimeLayout = "2006/01/02 15:04:05 -0700"
+
+// ParseTime parses a timestamp from an API payload.
+func ParseTime(raw string) (time.Time, error) {
+	return time.Parse(TimeLayout, raw)
+}
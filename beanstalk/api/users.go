@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// UsersService manages the users in an account. New users are added by
+// inviting them, using InvitationsService.
+type UsersService struct {
+	client *Client
+}
+
+func (s *UsersService) Get(ctx context.Context, id int) (*User, error) {
+	if s.client.cache != nil {
+		item, ok, err := s.client.cache.Get(ctx, "users", id, func(ctx context.Context, add func(int, interface{})) error {
+			return s.Each(ctx, func(user *User) (bool, error) {
+				add(user.ID, *user)
+				return true, nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			user := item.(User)
+			return &user, nil
+		}
+	}
+
+	var res UserWrap
+	err := s.client.Get(ctx, []string{"users", strconv.Itoa(id)}, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.User, nil
+}
+
+// Each calls fn for each user in the account, stopping early if fn
+// returns false or an error.
+func (s *UsersService) Each(ctx context.Context, fn func(*User) (bool, error)) error {
+	var res []UserWrap
+	return s.client.GetPages(ctx, []string{"users"}, nil, &res, func() (bool, error) {
+		for i := range res {
+			more, err := fn(&res[i].User)
+			if err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// FindByEmail searches the user list for the user with the given email
+// address, returning a NotFoundError if there is no such user. Email
+// addresses are compared case-insensitively, as Beanstalk does.
+func (s *UsersService) FindByEmail(ctx context.Context, email string) (*User, error) {
+	var found *User
+	err := s.Each(ctx, func(user *User) (bool, error) {
+		if strings.EqualFold(user.Email, email) {
+			u := *user
+			found = &u
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, &NotFoundError{}
+	}
+	return found, nil
+}
+
+func (s *UsersService) Update(ctx context.Context, id int, user *User) error {
+	req := &UserWrap{
+		User: *user,
+	}
+
+	s.forget(id)
+	return s.client.Put(ctx, []string{"users", strconv.Itoa(id)}, req, nil)
+}
+
+func (s *UsersService) Delete(ctx context.Context, id int) error {
+	s.forget(id)
+	if s.client.cache != nil {
+		// Deleting a user also removes them from their teams.
+		s.client.cache.Invalidate("teams")
+	}
+	return s.client.Delete(ctx, []string{"users", strconv.Itoa(id)})
+}
+
+func (s *UsersService) forget(id int) {
+	if s.client.cache != nil {
+		s.client.cache.Forget("users", id)
+	}
+}
+
+type User struct {
+	ID             int    `json:"id,omitempty"`
+	Username       string `json:"login"`
+	Email          string `json:"email"`
+	Name           string `json:"name,omitempty"`
+	Timezone       string `json:"timezone,omitempty"`
+	IsAccountAdmin bool   `json:"admin"`
+
+	// Ownership is changed via AccountService rather than by updating
+	// users, so a false value is never sent.
+	IsAccountOwner bool `json:"owner,omitempty"`
+
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+type UserWrap struct {
+	User User `json:"user"`
+}
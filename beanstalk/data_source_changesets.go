@@ -0,0 +1,141 @@
+package beanstalk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func dataSourceChangesets() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadChangesets,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+
+			"branch": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"since": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRFC3339,
+			},
+
+			"until": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRFC3339,
+			},
+
+			"limit": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  api.PageSize,
+			},
+
+			"changesets": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"repository_id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"revision": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"author": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"email": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"message": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"time": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"changed_files_count": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"changed_dirs_count": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ReadChangesets(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	filter := &api.ChangesetFilter{
+		RepositoryID: d.Get("repository_id").(int),
+		Branch:       d.Get("branch").(string),
+	}
+	if filter.Branch != "" && filter.RepositoryID == 0 {
+		return fmt.Errorf("branch can be used only when repository_id is also set")
+	}
+	if v, ok := d.GetOk("since"); ok {
+		filter.Since, _ = time.Parse(time.RFC3339, v.(string))
+	}
+	if v, ok := d.GetOk("until"); ok {
+		filter.Until, _ = time.Parse(time.RFC3339, v.(string))
+	}
+
+	limit := d.Get("limit").(int)
+	changesets := []map[string]interface{}{}
+	err := client.Changesets.Each(ctx, filter, func(changeset *api.Changeset) (bool, error) {
+		if len(changesets) >= limit {
+			return false, nil
+		}
+		changesets = append(changesets, map[string]interface{}{
+			"repository_id":       changeset.RepositoryID,
+			"revision":            changeset.Revision,
+			"author":              changeset.Author,
+			"email":               changeset.Email,
+			"user_id":             changeset.UserID,
+			"message":             changeset.Message,
+			"time":                formatTimestamp(changeset.RawTime),
+			"changed_files_count": len(changeset.ChangedFiles),
+			"changed_dirs_count":  len(changeset.ChangedDirs),
+		})
+		return len(changesets) < limit, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf(
+		"%d/%s/%s/%s",
+		filter.RepositoryID, filter.Branch,
+		d.Get("since").(string), d.Get("until").(string),
+	))
+	d.Set("changesets", changesets)
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package beanstalk
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func dataSourceReleases() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadReleases,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+
+			"environment_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+
+			"limit": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  api.PageSize,
+			},
+
+			"releases": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"environment_id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"environment_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"revision": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"previous_revision": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"comment": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"deployed_by": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"deployed_by_user_id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"created_at": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated_at": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ReadReleases(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	environmentId := d.Get("environment_id").(int)
+	limit := d.Get("limit").(int)
+
+	// Beanstalk returns releases newest first, so we can stop fetching
+	// pages once we have enough.
+	releases := []map[string]interface{}{}
+	if limit > 0 {
+		err := client.Releases.Each(ctx, repositoryId, environmentId, func(release *api.Release) (bool, error) {
+			releases = append(releases, flattenRelease(release))
+			return len(releases) < limit, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	id := strconv.Itoa(repositoryId)
+	if environmentId != 0 {
+		id = id + "/" + strconv.Itoa(environmentId)
+	}
+	d.SetId(id)
+	d.Set("releases", releases)
+
+	return nil
+}
+
+func flattenRelease(release *api.Release) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  release.ID,
+		"environment_id":      release.EnvironmentID,
+		"environment_name":    release.EnvironmentName,
+		"revision":            release.Revision,
+		"previous_revision":   release.EnvironmentRevision,
+		"status":              release.State,
+		"comment":             release.Comment,
+		"deployed_by":         release.Author,
+		"deployed_by_user_id": release.UserID,
+		"created_at":          formatTimestamp(release.CreatedAt),
+		"updated_at":          formatTimestamp(release.UpdatedAt),
+	}
+}
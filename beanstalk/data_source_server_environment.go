@@ -0,0 +1,123 @@
+package beanstalk
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func dataSourceServerEnvironment() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadServerEnvironment,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+
+			"environment_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"branch_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"color_label": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"automatic": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"current_revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_release_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"last_release_status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_release_revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_deployed_by": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_deployed_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadServerEnvironment(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	environmentId := d.Get("environment_id").(int)
+
+	environment, err := client.ServerEnvironments.Get(ctx, repositoryId, environmentId)
+	if err != nil {
+		return err
+	}
+
+	// Releases are returned newest first, so we need only the first one.
+	var lastRelease *api.Release
+	err = client.Releases.Each(ctx, repositoryId, environmentId, func(release *api.Release) (bool, error) {
+		r := *release
+		lastRelease = &r
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(repositoryId) + "/" + strconv.Itoa(environmentId))
+	d.Set("name", environment.Name)
+	d.Set("branch_name", environment.BranchName)
+	d.Set("color_label", environment.ColorLabel)
+	d.Set("automatic", environment.Automatic)
+	d.Set("current_revision", environment.CurrentVersion)
+
+	if lastRelease != nil {
+		d.Set("last_release_id", lastRelease.ID)
+		d.Set("last_release_status", lastRelease.State)
+		d.Set("last_release_revision", lastRelease.Revision)
+		d.Set("last_deployed_by", lastRelease.Author)
+		d.Set("last_deployed_at", formatTimestamp(lastRelease.UpdatedAt))
+	} else {
+		d.Set("last_release_id", 0)
+		d.Set("last_release_status", "")
+		d.Set("last_release_revision", "")
+		d.Set("last_deployed_by", "")
+		d.Set("last_deployed_at", "")
+	}
+
+	return nil
+}
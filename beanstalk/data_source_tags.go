@@ -0,0 +1,67 @@
+package beanstalk
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func dataSourceTags() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadTags,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+
+			"tags": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"revision": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ReadTags(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+
+	tags := []map[string]interface{}{}
+	err := client.Tags.Each(ctx, repositoryId, func(tag *api.Tag) (bool, error) {
+		tags = append(tags, map[string]interface{}{
+			"name":     tag.Name,
+			"revision": tag.Revision,
+			"message":  tag.Message,
+		})
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(repositoryId))
+	d.Set("tags", tags)
+
+	return nil
+}
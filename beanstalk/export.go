@@ -0,0 +1,401 @@
+package beanstalk
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+// Export is a description of an existing Beanstalk account as Terraform
+// configuration, for adopting an account that was set up by hand.
+type Export struct {
+	// Files maps the names of .tf files to their contents.
+	Files map[string][]byte
+
+	// ImportCommands are the "terraform import" commands that bring each
+	// of the exported objects under management.
+	ImportCommands []string
+}
+
+// ExportAccount walks the repositories, users, teams, code review settings
+// and integrations of the account that the client is configured for and
+// describes them as Terraform configuration. Objects refer to one another
+// by resource address rather than by literal id, so that the result can
+// be maintained as if it had been written by hand.
+func ExportAccount(ctx context.Context, client *api.Client) (*Export, error) {
+	e := &exporter{
+		client:          client,
+		usedNames:       map[string]bool{},
+		repositoryNames: map[int]string{},
+		userNames:       map[int]string{},
+		teamNames:       map[int]string{},
+	}
+
+	steps := []func(context.Context) error{
+		e.exportRepositories,
+		e.exportUsers,
+		e.exportTeams,
+		e.exportCodeReviewSettings,
+		e.exportIntegrations,
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	writers := []*hclWriter{
+		&e.repositories, &e.users, &e.teams, &e.codeReviews, &e.integrations, &e.variables,
+	}
+	for _, w := range writers {
+		if err := w.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	files := map[string][]byte{
+		"repositories.tf": e.repositories.Bytes(),
+		"users.tf":        e.users.Bytes(),
+		"teams.tf":        e.teams.Bytes(),
+	}
+	if len(e.codeReviews.Bytes()) > 0 {
+		files["code_review_settings.tf"] = e.codeReviews.Bytes()
+	}
+	if len(e.integrations.Bytes()) > 0 {
+		files["integrations.tf"] = e.integrations.Bytes()
+	}
+	if len(e.variables.Bytes()) > 0 {
+		files["variables.tf"] = e.variables.Bytes()
+	}
+
+	return &Export{
+		Files:          files,
+		ImportCommands: e.imports,
+	}, nil
+}
+
+type exporter struct {
+	client *api.Client
+
+	repositories hclWriter
+	users        hclWriter
+	teams        hclWriter
+	codeReviews  hclWriter
+	integrations hclWriter
+	variables    hclWriter
+	imports      []string
+
+	// usedNames records the addresses already assigned, and the other
+	// maps record the resource name assigned to each object by id, so
+	// that later objects can refer to earlier ones.
+	usedNames       map[string]bool
+	repositoryNames map[int]string
+	userNames       map[int]string
+	teamNames       map[int]string
+
+	// The repositories are kept for the steps that visit each one.
+	allRepositories []*api.Repository
+	gitRepositories []*api.Repository
+}
+
+var resourceNameInvalidChars = regexp.MustCompile("[^a-z0-9_]+")
+
+// resourceName derives a unique Terraform resource name from the name
+// of a Beanstalk object.
+func (e *exporter) resourceName(resourceType string, base string) string {
+	name := strings.Trim(resourceNameInvalidChars.ReplaceAllString(strings.ToLower(base), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	candidate := name
+	for i := 2; e.usedNames[resourceType+"."+candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", name, i)
+	}
+	e.usedNames[resourceType+"."+candidate] = true
+	return candidate
+}
+
+func (e *exporter) addImport(resourceType, name, id string) {
+	e.imports = append(e.imports, fmt.Sprintf("terraform import %s.%s %s", resourceType, name, id))
+}
+
+// The reference helpers fall back to the literal id if the object wasn't
+// exported, which can happen if it was created during the export.
+
+func (e *exporter) repositoryReference(id int, attr string) interface{} {
+	if name, ok := e.repositoryNames[id]; ok {
+		return hclReference("beanstalk_repository." + name + "." + attr)
+	}
+	return id
+}
+
+func (e *exporter) userReference(id int) interface{} {
+	if name, ok := e.userNames[id]; ok {
+		return hclReference("beanstalk_user." + name + ".id")
+	}
+	return id
+}
+
+func (e *exporter) teamReference(id int) interface{} {
+	if name, ok := e.teamNames[id]; ok {
+		return hclReference("beanstalk_team." + name + ".id")
+	}
+	return id
+}
+
+func (e *exporter) exportRepositories(ctx context.Context) error {
+	return e.client.Repositories.Each(ctx, func(repo *api.Repository) (bool, error) {
+		name := e.resourceName("beanstalk_repository", repo.Name)
+		e.repositoryNames[repo.ID] = name
+		e.allRepositories = append(e.allRepositories, repo)
+
+		w := &e.repositories
+		w.openBlock("resource", "beanstalk_repository", name)
+		w.attr("title", repo.Title)
+		w.attr("name", repo.Name)
+		w.attr("color_label", repo.ColorLabel)
+		w.attr("vcs", repo.VCS)
+		if repo.VCS == "git" {
+			w.attr("default_git_branch", repo.DefaultGitBranch)
+			e.gitRepositories = append(e.gitRepositories, repo)
+		}
+		w.closeBlock()
+
+		e.addImport("beanstalk_repository", name, strconv.Itoa(repo.ID))
+		return true, nil
+	})
+}
+
+func (e *exporter) exportUsers(ctx context.Context) error {
+	return e.client.Users.Each(ctx, func(user *api.User) (bool, error) {
+		name := e.resourceName("beanstalk_user", user.Username)
+		e.userNames[user.ID] = name
+
+		w := &e.users
+		w.openBlock("resource", "beanstalk_user", name)
+		w.attr("username", user.Username)
+		w.attr("name", user.Name)
+		w.attr("email", user.Email)
+		w.attr("account_admin", user.IsAccountAdmin)
+		w.attr("timezone", user.Timezone)
+		w.closeBlock()
+
+		e.addImport("beanstalk_user", name, strconv.Itoa(user.ID))
+		return true, nil
+	})
+}
+
+func (e *exporter) exportTeams(ctx context.Context) error {
+	return e.client.Teams.Each(ctx, func(team *api.TeamRead) (bool, error) {
+		name := e.resourceName("beanstalk_team", team.Name)
+		e.teamNames[team.ID] = name
+
+		userIds := make([]interface{}, len(team.Users))
+		for i, user := range team.Users {
+			userIds[i] = e.userReference(user.ID)
+		}
+
+		w := &e.teams
+		w.openBlock("resource", "beanstalk_team", name)
+		w.attr("name", team.Name)
+		w.attr("color_label", team.ColorLabel)
+		w.attr("user_ids", userIds)
+		for _, permissions := range team.Permissions {
+			w.openBlock("repository_permissions")
+			w.attr("repository_id", e.repositoryReference(permissions.RepositoryID, "id"))
+			w.attr("can_write", permissions.CanWrite)
+			w.attr("can_deploy", permissions.CanDeploy)
+			w.attr("can_configure_deployments", permissions.CanConfigureDeployments)
+			w.closeBlock()
+		}
+		w.closeBlock()
+
+		e.addImport("beanstalk_team", name, strconv.Itoa(team.ID))
+		return true, nil
+	})
+}
+
+func (e *exporter) exportCodeReviewSettings(ctx context.Context) error {
+	// Code review is available only for git repositories. Every git
+	// repository has settings, so only those changed from the defaults
+	// are exported.
+	for _, repo := range e.gitRepositories {
+		settings, err := e.client.CodeReviews.GetSettings(ctx, repo.ID)
+		if err != nil {
+			if api.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		assigneeUserIds := []interface{}{}
+		for _, assignee := range settings.DefaultAssignees {
+			assigneeUserIds = append(assigneeUserIds, e.userReference(assignee.ID))
+		}
+		watcherUserIds := []interface{}{}
+		watcherTeamIds := []interface{}{}
+		for _, watcher := range settings.DefaultWatchers {
+			switch watcher.Type {
+			case "User":
+				watcherUserIds = append(watcherUserIds, e.userReference(watcher.ID))
+			case "Team":
+				watcherTeamIds = append(watcherTeamIds, e.teamReference(watcher.ID))
+			}
+		}
+
+		isDefault := !settings.UnanimousApproval && !settings.AutoReopen &&
+			len(assigneeUserIds) == 0 && len(watcherUserIds) == 0 && len(watcherTeamIds) == 0
+		if isDefault {
+			continue
+		}
+
+		name := e.resourceName("beanstalk_repository_code_review_settings", repo.Name)
+
+		w := &e.codeReviews
+		w.openBlock("resource", "beanstalk_repository_code_review_settings", name)
+		w.attr("repository_id", e.repositoryReference(repo.ID, "id"))
+		w.attr("unanimous_approval", settings.UnanimousApproval)
+		w.attr("auto_reopen", settings.AutoReopen)
+		w.attr("default_assignee_user_ids", assigneeUserIds)
+		w.attr("default_watching_user_ids", watcherUserIds)
+		w.attr("default_watching_team_ids", watcherTeamIds)
+		w.closeBlock()
+
+		e.addImport("beanstalk_repository_code_review_settings", name, strconv.Itoa(repo.ID))
+	}
+
+	return nil
+}
+
+func (e *exporter) exportIntegrations(ctx context.Context) error {
+	resourceTypes := map[string]string{}
+	types := integrationTypes()
+	for resourceType, it := range types {
+		resourceTypes[it.Name] = resourceType
+	}
+
+	for _, repo := range e.allRepositories {
+		err := e.client.Integrations.Each(ctx, repo.Name, func(data map[string]interface{}) (bool, error) {
+			typeName, _ := data["type"].(string)
+			idFloat, _ := data["id"].(float64)
+			id := int(idFloat)
+
+			resourceType, ok := resourceTypes[typeName]
+			if !ok {
+				e.integrations.comment(fmt.Sprintf(
+					"Integration %d of repository %s has type %s, which this plugin does not support.",
+					id, repo.Name, typeName,
+				))
+				e.integrations.line("")
+				return true, nil
+			}
+			it := types[resourceType]
+
+			name := e.resourceName(resourceType, repo.Name+"_"+strings.TrimSuffix(resourceType[len("beanstalk_"):], "_integration"))
+
+			writeOnly := map[string]bool{}
+			for _, k := range it.WriteOnlyAttributes {
+				writeOnly[k] = true
+			}
+
+			w := &e.integrations
+			w.openBlock("resource", resourceType, name)
+			w.attr("repository_name", e.repositoryReference(repo.ID, "name"))
+			for _, k := range sortedSchemaKeys(it.Attributes) {
+				if writeOnly[k] {
+					// Secrets can't be read back from the API, so they
+					// must be supplied as variables.
+					variable := name + "_" + k
+					e.variables.openBlock("variable", variable)
+					e.variables.closeBlock()
+					w.attr(k, hclReference("var."+variable))
+					continue
+				}
+				if data[k] == nil {
+					continue
+				}
+				value, err := decodeFromJSON(it.Attributes[k], data[k])
+				if err != nil {
+					err = fmt.Errorf("%s: %s", k, err)
+				} else {
+					err = writeSchemaValue(w, k, it.Attributes[k], value)
+				}
+				if err != nil {
+					return false, fmt.Errorf(
+						"can't export integration %d of repository %s: %s",
+						id, repo.Name, err,
+					)
+				}
+			}
+			w.closeBlock()
+
+			e.addImport(resourceType, name, repo.Name+"/"+strconv.Itoa(id))
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSchemaValue writes an attribute value that was decoded from the
+// API according to its schema, in the same way as refreshFromJSON would
+// store it in the state. Values of unexpected types are reported as
+// errors rather than written.
+func writeSchemaValue(w *hclWriter, k string, s *schema.Schema, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case schema.TypeInt:
+		if f, ok := value.(float64); ok {
+			value = int(f)
+		}
+		w.attr(k, value)
+	case schema.TypeList:
+		resource, ok := s.Elem.(*schema.Resource)
+		if !ok {
+			w.attr(k, value)
+			break
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected a list, got %T", k, value)
+		}
+		for _, itemI := range items {
+			item, ok := itemI.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: expected a block, got %T", k, itemI)
+			}
+			w.openBlock(k)
+			for _, ik := range sortedSchemaKeys(resource.Schema) {
+				if err := writeSchemaValue(w, ik, resource.Schema[ik], item[ik]); err != nil {
+					return err
+				}
+			}
+			w.closeBlock()
+		}
+	default:
+		w.attr(k, value)
+	}
+	return w.Err()
+}
+
+func sortedSchemaKeys(m map[string]*schema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
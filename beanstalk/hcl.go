@@ -0,0 +1,102 @@
+package beanstalk
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hclWriter produces Terraform configuration in the same style that is
+// used in this plugin's documentation. It supports only the handful of
+// constructs needed to describe Beanstalk objects. As with csv.Writer,
+// the first error is remembered and reported by Err.
+type hclWriter struct {
+	buf    bytes.Buffer
+	indent int
+	err    error
+}
+
+// hclReference is an expression that refers to an attribute of another
+// object, such as "beanstalk_user.example.id". It's written as an
+// interpolation rather than as a literal string.
+type hclReference string
+
+func (w *hclWriter) line(format string, args ...interface{}) {
+	w.buf.WriteString(strings.Repeat("    ", w.indent))
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteString("\n")
+}
+
+func (w *hclWriter) comment(text string) {
+	w.line("# %s", text)
+}
+
+func (w *hclWriter) openBlock(labels ...string) {
+	header := labels[0]
+	for _, label := range labels[1:] {
+		header += " " + hclString(label)
+	}
+	w.line("%s {", header)
+	w.indent++
+}
+
+func (w *hclWriter) closeBlock() {
+	w.indent--
+	w.line("}")
+	if w.indent == 0 {
+		w.buf.WriteString("\n")
+	}
+}
+
+func (w *hclWriter) attr(name string, value interface{}) {
+	s, err := hclValue(value)
+	if err != nil {
+		if w.err == nil {
+			w.err = fmt.Errorf("%s: %s", name, err)
+		}
+		return
+	}
+	w.line("%s = %s", name, s)
+}
+
+func (w *hclWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// Err returns the first error that occurred while writing, if any.
+func (w *hclWriter) Err() error {
+	return w.err
+}
+
+func hclValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return hclString(v), nil
+	case hclReference:
+		return "\"${" + string(v) + "}\"", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := hclValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("can't write %T as HCL", value)
+	}
+}
+
+func hclString(s string) string {
+	// A literal "${" would otherwise begin an interpolation.
+	return strings.Replace(strconv.Quote(s), "${", "$${", -1)
+}
@@ -1,22 +1,39 @@
 package beanstalk
 
 import (
+	"context"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		ResourcesMap: map[string]*schema.Resource{
+			"beanstalk_account_ownership_transfer":      resourceAccountOwnershipTransfer(),
+			"beanstalk_branch":                          resourceBranch(),
+			"beanstalk_branch_restriction":              resourceBranchRestriction(),
 			"beanstalk_hipchat_integration":             resourceHipchatIntegration(),
 			"beanstalk_jira_integration":                resourceJiraIntegration(),
 			"beanstalk_modular_webhook_integration":     resourceModularWebhookIntegration(),
+			"beanstalk_release":                         resourceRelease(),
 			"beanstalk_repository":                      resourceRepository(),
 			"beanstalk_repository_code_review_settings": resourceRepositoryCodeReviewSettings(),
 			"beanstalk_user":                            resourceUser(),
+			"beanstalk_user_roster":                     resourceUserRoster(),
+			"beanstalk_tag":                             resourceTag(),
 			"beanstalk_team":                            resourceTeam(),
 		},
 
+		DataSourcesMap: map[string]*schema.Resource{
+			"beanstalk_changesets":         dataSourceChangesets(),
+			"beanstalk_releases":           dataSourceReleases(),
+			"beanstalk_server_environment": dataSourceServerEnvironment(),
+			"beanstalk_tags":               dataSourceTags(),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"account_name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -32,17 +49,115 @@ func Provider() terraform.ResourceProvider {
 				Required:    true,
 				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_ACCESS_TOKEN", nil),
 			},
+			"request_timeout": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_REQUEST_TIMEOUT", int(api.DefaultTimeout/time.Second)),
+			},
+			"log_request_bodies": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_LOG_REQUEST_BODIES", false),
+			},
+			"max_concurrent_requests": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_MAX_CONCURRENT_REQUESTS", 0),
+			},
+			"max_requests_per_second": &schema.Schema{
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_MAX_REQUESTS_PER_SECOND", 0.0),
+			},
+
+			"cache_list_reads": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_CACHE_LIST_READS", false),
+			},
+			"conditional_requests": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BEANSTALK_CONDITIONAL_REQUESTS", false),
+			},
 		},
+	}
+
+	sensitiveFields := sensitiveAttributeNames(p.ResourcesMap)
+
+	// The stop context is cancelled when Terraform is interrupted, which
+	// aborts any requests that are in progress.
+	p.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(d, p.StopContext(), sensitiveFields)
+	}
+
+	return p
+}
+
+func providerConfigure(d *schema.ResourceData, stopCtx context.Context, sensitiveFields []string) (interface{}, error) {
+	config := &api.ClientConfig{
+		AccountName:     d.Get("account_name").(string),
+		Username:        d.Get("username").(string),
+		AccessToken:     d.Get("access_token").(string),
+		Timeout:         time.Duration(d.Get("request_timeout").(int)) * time.Second,
+		LogBodies:       d.Get("log_request_bodies").(bool),
+		SensitiveFields: sensitiveFields,
+
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+		MaxRequestsPerSecond:  d.Get("max_requests_per_second").(float64),
+		CacheListReads:        d.Get("cache_list_reads").(bool),
+		ConditionalRequests:   d.Get("conditional_requests").(bool),
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providerMeta{
+		client:  client,
+		stopCtx: stopCtx,
+	}, nil
+}
+
+type providerMeta struct {
+	client  *api.Client
+	stopCtx context.Context
+}
+
+// clientContext returns the API client along with a context for a single
+// resource operation. The context is cancelled if Terraform is interrupted
+// or if the operation outlasts the timeout configured for it.
+func clientContext(d *schema.ResourceData, meta interface{}, timeoutKey string) (*api.Client, context.Context, context.CancelFunc) {
+	m := meta.(*providerMeta)
+	ctx, cancel := context.WithTimeout(m.stopCtx, d.Timeout(timeoutKey))
+	return m.client, ctx, cancel
+}
 
-		ConfigureFunc: providerConfigure,
+// defaultResourceTimeouts returns the operation timeouts used by most
+// resources, which can be overridden with a "timeouts" block.
+func defaultResourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(5 * time.Minute),
+		Update: schema.DefaultTimeout(5 * time.Minute),
+		Delete: schema.DefaultTimeout(5 * time.Minute),
 	}
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	config := &ClientConfig{
-		AccountName: d.Get("account_name").(string),
-		Username:    d.Get("username").(string),
-		AccessToken: d.Get("access_token").(string),
+// sensitiveAttributeNames returns the names of all of the sensitive
+// attributes across the given resources, so that the client can mask them
+// in its debug logs. Resource attributes generally share the names of the
+// API fields they correspond to.
+func sensitiveAttributeNames(resources map[string]*schema.Resource) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, resource := range resources {
+		for name, s := range resource.Schema {
+			if s.Sensitive && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
 	}
-	return NewClient(config)
+	return names
 }
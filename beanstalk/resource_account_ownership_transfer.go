@@ -0,0 +1,99 @@
+package beanstalk
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func resourceAccountOwnershipTransfer() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateAccountOwnershipTransfer,
+		Read:   ReadAccountOwnershipTransfer,
+		Update: UpdateAccountOwnershipTransfer,
+		Delete: DeleteAccountOwnershipTransfer,
+
+		Timeouts: defaultResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"owner_user_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+
+			"previous_owner_user_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateAccountOwnershipTransfer(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	account, err := client.Account.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	// There is only one account, so its id identifies this resource.
+	d.SetId(strconv.Itoa(account.ID))
+
+	return updateAccountOwnershipTransfer(ctx, client, d)
+}
+
+func ReadAccountOwnershipTransfer(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readAccountOwnershipTransfer(ctx, client, d)
+}
+
+func readAccountOwnershipTransfer(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	account, err := client.Account.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	// If ownership has since moved elsewhere, this produces a diff that
+	// will move it back.
+	d.Set("owner_user_id", account.OwnerID)
+
+	return nil
+}
+
+func UpdateAccountOwnershipTransfer(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+	return updateAccountOwnershipTransfer(ctx, client, d)
+}
+
+func updateAccountOwnershipTransfer(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	account, err := client.Account.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	ownerId := d.Get("owner_user_id").(int)
+	if account.OwnerID != ownerId {
+		err := client.Account.Update(ctx, &api.Account{
+			OwnerID: ownerId,
+		})
+		if err != nil {
+			return err
+		}
+		d.Set("previous_owner_user_id", account.OwnerID)
+	}
+
+	return readAccountOwnershipTransfer(ctx, client, d)
+}
+
+func DeleteAccountOwnershipTransfer(d *schema.ResourceData, meta interface{}) error {
+	// An account must always have an owner, so destroying this resource
+	// just leaves ownership where it is.
+	d.SetId("")
+	return nil
+}
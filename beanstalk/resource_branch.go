@@ -0,0 +1,129 @@
+package beanstalk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func resourceBranch() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateBranch,
+		Read:   ReadBranch,
+		Delete: DeleteBranch,
+
+		Timeouts: defaultResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"ref": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateBranch(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	name := d.Get("name").(string)
+
+	err := client.Branches.Create(ctx, repositoryId, name, d.Get("ref").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(repositoryRefId(repositoryId, name))
+
+	return readBranch(ctx, client, d)
+}
+
+func ReadBranch(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readBranch(ctx, client, d)
+}
+
+func readBranch(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	repositoryId, name, err := parseRepositoryRefId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	branch, err := client.Branches.Get(ctx, repositoryId, name)
+	if err != nil {
+		if api.IsNotFound(err) {
+			// Either the branch or its whole repository was deleted
+			// outside of Terraform.
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("name", branch.Name)
+	d.Set("revision", branch.Revision)
+
+	return nil
+}
+
+func DeleteBranch(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	repositoryId, name, err := parseRepositoryRefId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = client.Branches.Delete(ctx, repositoryId, name)
+	if err != nil && !api.IsNotFound(err) {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// Branches and tags don't have ids of their own in Beanstalk, so their
+// resource ids combine the repository id with the ref name. Ref names may
+// contain slashes, but repository ids never do.
+func repositoryRefId(repositoryId int, name string) string {
+	return strconv.Itoa(repositoryId) + "/" + name
+}
+
+func parseRepositoryRefId(id string) (int, string, error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, "", fmt.Errorf("invalid id %q; must be <repository_id>/<name>", id)
+	}
+	repositoryId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid id %q; must be <repository_id>/<name>", id)
+	}
+	return repositoryId, parts[1], nil
+}
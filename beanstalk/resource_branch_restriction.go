@@ -0,0 +1,166 @@
+package beanstalk
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func resourceBranchRestriction() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateBranchRestriction,
+		Read:   ReadBranchRestriction,
+		Update: UpdateBranchRestriction,
+		Delete: DeleteBranchRestriction,
+
+		Timeouts: defaultResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"branch_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"user_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Set: func(v interface{}) int {
+					return v.(int)
+				},
+			},
+
+			"team_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Set: func(v interface{}) int {
+					return v.(int)
+				},
+			},
+		},
+	}
+}
+
+func CreateBranchRestriction(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+
+	restriction, err := client.BranchRestrictions.Create(ctx, repositoryId, branchRestrictionFromResourceData(d))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(restriction.ID))
+
+	return readBranchRestriction(ctx, client, d)
+}
+
+func ReadBranchRestriction(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readBranchRestriction(ctx, client, d)
+}
+
+func readBranchRestriction(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	repositoryId := d.Get("repository_id").(int)
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	restriction, err := client.BranchRestrictions.Get(ctx, repositoryId, id)
+	if err != nil {
+		if api.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	// Deleting a branch doesn't necessarily remove its restriction, so
+	// the branch itself is checked too, to notice when it's gone.
+	_, err = client.Branches.Get(ctx, repositoryId, restriction.BranchName)
+	if err != nil {
+		if api.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("branch_name", restriction.BranchName)
+	d.Set("user_ids", restriction.UserIDs)
+	d.Set("team_ids", restriction.TeamIDs)
+
+	return nil
+}
+
+func UpdateBranchRestriction(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = client.BranchRestrictions.Update(ctx, repositoryId, id, branchRestrictionFromResourceData(d))
+	if err != nil {
+		return err
+	}
+
+	return readBranchRestriction(ctx, client, d)
+}
+
+func DeleteBranchRestriction(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = client.BranchRestrictions.Delete(ctx, repositoryId, id)
+	if err != nil && !api.IsNotFound(err) {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func branchRestrictionFromResourceData(d *schema.ResourceData) *api.BranchRestriction {
+	setToInts := func(s *schema.Set) []int {
+		in := s.List()
+		ret := make([]int, len(in))
+		for i, si := range in {
+			ret[i] = si.(int)
+		}
+		return ret
+	}
+
+	return &api.BranchRestriction{
+		BranchName: d.Get("branch_name").(string),
+		UserIDs:    setToInts(d.Get("user_ids").(*schema.Set)),
+		TeamIDs:    setToInts(d.Get("team_ids").(*schema.Set)),
+	}
+}
@@ -5,13 +5,16 @@ import (
 )
 
 func resourceHipchatIntegration() *schema.Resource {
-	integrationType := &integrationType{
+	return hipchatIntegrationType().resource()
+}
+
+func hipchatIntegrationType() *integrationType {
+	return &integrationType{
 		Name: "HipchatIntegration",
 		Attributes: map[string]*schema.Schema{
 			"service_access_token": &schema.Schema{
 				Type:      schema.TypeString,
 				Required:  true,
-				ForceNew:  true,
 				StateFunc: hashForState,
 			},
 			"service_room_name": &schema.Schema{
@@ -31,6 +34,4 @@ func resourceHipchatIntegration() *schema.Resource {
 		},
 		WriteOnlyAttributes: []string{"service_access_token"},
 	}
-
-	return integrationType.resource()
 }
@@ -1,11 +1,15 @@
 package beanstalk
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
 type integrationType struct {
@@ -19,6 +23,16 @@ type integrationType struct {
 // implementation here is abstract and is instantiated for each of the
 // physical resources.
 
+// integrationTypes returns the definition of each integration type, keyed
+// by the name of its resource type.
+func integrationTypes() map[string]*integrationType {
+	return map[string]*integrationType{
+		"beanstalk_hipchat_integration":         hipchatIntegrationType(),
+		"beanstalk_jira_integration":            jiraIntegrationType(),
+		"beanstalk_modular_webhook_integration": modularWebhookIntegrationType(),
+	}
+}
+
 func (it *integrationType) resource() *schema.Resource {
 	resourceSchema := map[string]*schema.Schema{
 		"id": &schema.Schema{
@@ -35,88 +49,116 @@ func (it *integrationType) resource() *schema.Resource {
 		resourceSchema[k] = v
 	}
 
+	// Write-only attributes are secrets that can't be read back from the
+	// API, so they are also kept out of plan output and debug logs. They
+	// are updated in place, which also means that an imported
+	// integration, which has no record of them, just sends them once on
+	// the next apply rather than being replaced.
+	for _, k := range it.WriteOnlyAttributes {
+		resourceSchema[k].Sensitive = true
+	}
+
 	return &schema.Resource{
 		Read: func(d *schema.ResourceData, meta interface{}) error {
-			client := meta.(*Client)
-			return it.Read(d, client)
+			client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+			defer cancel()
+			return it.Read(ctx, d, client)
 		},
 		Create: func(d *schema.ResourceData, meta interface{}) error {
-			client := meta.(*Client)
-			return it.Create(d, client)
+			client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+			defer cancel()
+			return it.Create(ctx, d, client)
 		},
 		Update: func(d *schema.ResourceData, meta interface{}) error {
-			client := meta.(*Client)
-			return it.Update(d, client)
+			client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+			defer cancel()
+			return it.Update(ctx, d, client)
 		},
 		Delete: func(d *schema.ResourceData, meta interface{}) error {
-			client := meta.(*Client)
-			return it.Delete(d, client)
+			client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+			defer cancel()
+			return it.Delete(ctx, d, client)
 		},
 
+		Importer: &schema.ResourceImporter{
+			State: importIntegration,
+		},
+
+		Timeouts: defaultResourceTimeouts(),
+
 		Schema: resourceSchema,
 	}
 }
 
-func (it *integrationType) Read(d *schema.ResourceData, client *Client) error {
-	repositoryName := d.Get("repository_name").(string)
-	integrationId := d.Id()
-
-	data := map[string]interface{}{}
-
-	err := client.Get([]string{"repositories", repositoryName, "integrations", integrationId}, nil, &data)
-	if err != nil {
-		return err
+// Integrations belong to a repository and can be read only via that
+// repository, so they are imported using ids of the form
+// "repository_name/integration_id".
+func importIntegration(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("integration id %q must be given as repository_name/integration_id", d.Id())
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return nil, fmt.Errorf("integration id %q must be given as repository_name/integration_id", d.Id())
 	}
 
-	it.refreshFromJSON(d, data["integration"].(map[string]interface{}))
+	d.SetId(parts[1])
+	d.Set("repository_name", parts[0])
 
-	return nil
+	return []*schema.ResourceData{d}, nil
 }
 
-func (it *integrationType) Create(d *schema.ResourceData, client *Client) error {
-	req := it.prepareForJSON(d)
-
-	type responseIntegration struct {
-		Id int `json:"id"`
+func (it *integrationType) Read(ctx context.Context, d *schema.ResourceData, client *api.Client) error {
+	repositoryName := d.Get("repository_name").(string)
+	integrationId, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
 	}
-	type response struct {
-		Integration responseIntegration `json:"integration"`
+
+	data, err := client.Integrations.Get(ctx, repositoryName, integrationId)
+	if err != nil {
+		return err
 	}
 
-	res := &response{}
+	return it.refreshFromJSON(d, data)
+}
 
+func (it *integrationType) Create(ctx context.Context, d *schema.ResourceData, client *api.Client) error {
 	repositoryName := d.Get("repository_name").(string)
 
-	err := client.Post([]string{"repositories", repositoryName, "integrations"}, req, res)
+	id, err := client.Integrations.Create(ctx, repositoryName, it.Name, it.prepareForJSON(d))
 	if err != nil {
 		return err
 	}
 
-	d.SetId(strconv.Itoa(res.Integration.Id))
+	d.SetId(strconv.Itoa(id))
 
 	return nil
 }
 
-func (it *integrationType) Update(d *schema.ResourceData, client *Client) error {
-	req := it.prepareForJSON(d)
-
+func (it *integrationType) Update(ctx context.Context, d *schema.ResourceData, client *api.Client) error {
 	repositoryName := d.Get("repository_name").(string)
-	integrationId := d.Id()
+	integrationId, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
 
-	return client.Put([]string{"repositories", repositoryName, "integrations", integrationId}, req, nil)
+	return client.Integrations.Update(ctx, repositoryName, integrationId, it.Name, it.prepareForJSON(d))
 }
 
-func (it *integrationType) Delete(d *schema.ResourceData, client *Client) error {
+func (it *integrationType) Delete(ctx context.Context, d *schema.ResourceData, client *api.Client) error {
 	repositoryName := d.Get("repository_name").(string)
-	integrationId := d.Id()
+	integrationId, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
 
-	return client.Delete([]string{"repositories", repositoryName, "integrations", integrationId})
+	return client.Integrations.Delete(ctx, repositoryName, integrationId)
 }
 
 func (it *integrationType) prepareForJSON(d *schema.ResourceData) map[string]interface{} {
 	ret := map[string]interface{}{}
 
-	ret["type"] = it.Name
 	isNew := d.IsNewResource()
 
 	for k, s := range it.Attributes {
@@ -125,12 +167,10 @@ func (it *integrationType) prepareForJSON(d *schema.ResourceData) map[string]int
 		}
 	}
 
-	return map[string]interface{}{
-		"integration": ret,
-	}
+	return ret
 }
 
-func (it *integrationType) refreshFromJSON(d *schema.ResourceData, data map[string]interface{}) {
+func (it *integrationType) refreshFromJSON(d *schema.ResourceData, data map[string]interface{}) error {
 	for k, s := range it.Attributes {
 		wo := false
 		for _, a := range it.WriteOnlyAttributes {
@@ -140,9 +180,14 @@ func (it *integrationType) refreshFromJSON(d *schema.ResourceData, data map[stri
 			}
 		}
 		if !wo {
-			d.Set(k, decodeFromJSON(s, data[k]))
+			value, err := decodeFromJSON(s, data[k])
+			if err != nil {
+				return err
+			}
+			d.Set(k, value)
 		}
 	}
+	return nil
 }
 
 func prepareForJSON(s *schema.Schema, value interface{}) interface{} {
@@ -175,7 +220,7 @@ func prepareForJSON(s *schema.Schema, value interface{}) interface{} {
 	return nil
 }
 
-func decodeFromJSON(s *schema.Schema, value interface{}) interface{} {
+func decodeFromJSON(s *schema.Schema, value interface{}) (interface{}, error) {
 	// This supports only what's required for the structures used by
 	// Beanstalk's integrations. Almost all fields are primitive types,
 	// but the modular webhook integration uses a nested object.
@@ -184,24 +229,35 @@ func decodeFromJSON(s *schema.Schema, value interface{}) interface{} {
 		if s.MaxItems == 1 {
 			elem := s.Elem
 			if resource, ok := elem.(*schema.Resource); ok {
+				if value == nil {
+					// A missing nested object is an absent block.
+					return nil, nil
+				}
+				valueMap, ok := value.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("expected an object, got %T", value)
+				}
 				ret := map[string]interface{}{}
-				valueMap := value.(map[string]interface{})
 				for k, s := range resource.Schema {
-					ret[k] = decodeFromJSON(s, valueMap[k])
+					v, err := decodeFromJSON(s, valueMap[k])
+					if err != nil {
+						return nil, fmt.Errorf("%s: %s", k, err)
+					}
+					ret[k] = v
 				}
-				return []interface{}{ret}
+				return []interface{}{ret}, nil
 			} else {
-				return value
+				return value, nil
 			}
 		}
 		break
 	default:
 		// No transformation for other types
-		return value
+		return value, nil
 	}
 
 	// Unreachable
-	return nil
+	return nil, nil
 }
 
 func hashForState(v interface{}) string {
@@ -5,7 +5,11 @@ import (
 )
 
 func resourceJiraIntegration() *schema.Resource {
-	integrationType := &integrationType{
+	return jiraIntegrationType().resource()
+}
+
+func jiraIntegrationType() *integrationType {
+	return &integrationType{
 		Name: "JiraIntegration",
 		Attributes: map[string]*schema.Schema{
 			"service_url": &schema.Schema{
@@ -16,13 +20,11 @@ func resourceJiraIntegration() *schema.Resource {
 			"service_login": &schema.Schema{
 				Type:      schema.TypeString,
 				Required:  true,
-				ForceNew:  true,
 				StateFunc: hashForState,
 			},
 			"service_password": &schema.Schema{
 				Type:      schema.TypeString,
 				Required:  true,
-				ForceNew:  true,
 				StateFunc: hashForState,
 			},
 			"service_project_name": &schema.Schema{
@@ -32,6 +34,4 @@ func resourceJiraIntegration() *schema.Resource {
 		},
 		WriteOnlyAttributes: []string{"service_login", "service_password"},
 	}
-
-	return integrationType.resource()
 }
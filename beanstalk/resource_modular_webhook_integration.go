@@ -5,7 +5,11 @@ import (
 )
 
 func resourceModularWebhookIntegration() *schema.Resource {
-	integrationType := &integrationType{
+	return modularWebhookIntegrationType().resource()
+}
+
+func modularWebhookIntegrationType() *integrationType {
+	return &integrationType{
 		Name: "ModularWebHooksIntegration",
 		Attributes: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -59,6 +63,4 @@ func resourceModularWebhookIntegration() *schema.Resource {
 			},
 		},
 	}
-
-	return integrationType.resource()
 }
@@ -0,0 +1,184 @@
+package beanstalk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func resourceRelease() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateRelease,
+		Read:   ReadRelease,
+		Delete: DeleteRelease,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"environment_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"comment": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"deploy_from_scratch": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+
+			"status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"log_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"deployed_revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateRelease(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	environmentId := d.Get("environment_id").(int)
+
+	release, err := client.Releases.Create(ctx, repositoryId, environmentId, &api.Release{
+		Revision:          d.Get("revision").(string),
+		Comment:           d.Get("comment").(string),
+		DeployFromScratch: d.Get("deploy_from_scratch").(bool),
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(release.ID))
+
+	// Beanstalk runs the deployment asynchronously, so we poll until it
+	// either succeeds or fails. A release is skipped when there was
+	// nothing to deploy, which is as good as success.
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"waiting", "pending"},
+		Target:  []string{"success", "skipped"},
+		Refresh: func() (interface{}, string, error) {
+			current, err := client.Releases.Get(ctx, repositoryId, release.ID)
+			if err != nil {
+				return nil, "", err
+			}
+			return current, current.State, nil
+		},
+		Timeout:    d.Timeout(schema.TimeoutCreate),
+		MinTimeout: 5 * time.Second,
+	}
+
+	_, waitErr := stateConf.WaitForState()
+
+	// Whatever the outcome, we record the final state of the release
+	// so that it can be inspected.
+	err = readRelease(ctx, client, d)
+	if err != nil {
+		return err
+	}
+
+	if waitErr != nil {
+		return fmt.Errorf(
+			"release %s did not succeed: %s; see %s for details",
+			d.Id(), waitErr, d.Get("log_url").(string),
+		)
+	}
+
+	return nil
+}
+
+func ReadRelease(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readRelease(ctx, client, d)
+}
+
+func readRelease(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	repositoryId := d.Get("repository_id").(int)
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	release, err := client.Releases.Get(ctx, repositoryId, id)
+	if err != nil {
+		if api.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	// The comment and deploy_from_scratch are kept as configured, since
+	// the latter isn't returned by Beanstalk and a release can't be
+	// changed anyway.
+	d.Set("environment_id", release.EnvironmentID)
+	d.Set("status", release.State)
+
+	// Beanstalk stores the full revision id even if the release was
+	// requested by branch name or abbreviated hash, so the configured
+	// revision is kept and the resolved one is exported separately.
+	d.Set("deployed_revision", release.Revision)
+
+	// The release log lives in the web UI, under a path that uses the
+	// repository name rather than its id. The URL never changes, so the
+	// repository is looked up only the first time.
+	if d.Get("log_url").(string) == "" {
+		repo, err := client.Repositories.Get(ctx, repositoryId)
+		if err != nil {
+			return err
+		}
+		d.Set("log_url", client.WebURL(
+			repo.Name,
+			"environments", strconv.Itoa(release.EnvironmentID),
+			"releases", d.Id(),
+		))
+	}
+
+	return nil
+}
+
+func DeleteRelease(d *schema.ResourceData, meta interface{}) error {
+	// A deployment cannot be undone, so deleting a release just means
+	// forgetting about it.
+	d.SetId("")
+	return nil
+}
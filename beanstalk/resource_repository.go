@@ -1,10 +1,12 @@
 package beanstalk
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
 func resourceRepository() *schema.Resource {
@@ -14,6 +16,12 @@ func resourceRepository() *schema.Resource {
 		Update: UpdateRepository,
 		Delete: DeleteRepository,
 
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: defaultResourceTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"title": &schema.Schema{
 				Type:     schema.TypeString,
@@ -21,34 +29,39 @@ func resourceRepository() *schema.Resource {
 			},
 
 			"name": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateRepositoryName,
 			},
 
 			"color_label": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "white",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "white",
+				ValidateFunc: validateColorLabel,
 			},
 
 			"default_git_branch": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "master",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "master",
+				DiffSuppressFunc: suppressUnlessVCS("git"),
 			},
 
 			"vcs": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "git",
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "git",
+				ForceNew:     true,
+				ValidateFunc: validateVCS,
 			},
 
 			"create_svn_structure": &schema.Schema{
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-				ForceNew: true,
+				Type:             schema.TypeBool,
+				Optional:         true,
+				Default:          false,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressCreateSVNStructure,
 			},
 
 			"id": &schema.Schema{
@@ -60,86 +73,144 @@ func resourceRepository() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+
+			"ssh_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"https_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"vcs_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"storage_used_bytes": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"created_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"updated_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_commit_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"svn_revision": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
 		},
 	}
 }
 
 func CreateRepository(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	req := &Repository{
+	repo, err := client.Repositories.Create(ctx, &api.Repository{
 		Title:              d.Get("title").(string),
 		Name:               d.Get("name").(string),
 		TypeID:             d.Get("vcs").(string),
 		CreateSVNStructure: d.Get("create_svn_structure").(bool),
-	}
-
-	res := &RepositoryWrap{}
-
-	err := client.Post([]string{"repositories"}, req, res)
+	})
 	if err != nil {
 		return err
 	}
 
-	id := strconv.Itoa(res.Repository.ID)
+	id := strconv.Itoa(repo.ID)
 	d.SetId(id)
 	d.Set("id", id)
 
-	return UpdateRepository(d, meta)
+	return updateRepository(ctx, client, d)
 }
 
 func ReadRepository(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readRepository(ctx, client, d)
+}
 
-	res := &RepositoryWrap{}
+func readRepository(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
 
-	err := client.Get([]string{"repositories", d.Id()}, nil, res)
+	repo, err := client.Repositories.Get(ctx, id)
 	if err != nil {
-		if _, ok := err.(*NotFoundError); ok {
+		if api.IsNotFound(err) {
 			d.SetId("")
 			d.Set("id", "")
 			return nil
-		} else {
-			return err
 		}
+		return err
 	}
 
-	d.Set("title", res.Repository.Title)
-	d.Set("name", res.Repository.Name)
-	d.Set("color_label", res.Repository.ColorLabel)
-	d.Set("default_git_branch", res.Repository.DefaultGitBranch)
-	d.Set("vcs", res.Repository.VCS)
-	d.Set("id", res.Repository.ID)
-	d.Set("url", res.Repository.URL)
+	d.Set("title", repo.Title)
+	d.Set("name", repo.Name)
+	d.Set("color_label", repo.ColorLabel)
+	d.Set("vcs", repo.VCS)
+	d.Set("id", repo.ID)
+	d.Set("url", repo.URL)
+	d.Set("vcs_type", repo.Type)
+	d.Set("revision", string(repo.Revision))
+	d.Set("storage_used_bytes", int(repo.StorageUsedBytes))
+	d.Set("created_at", formatTimestamp(repo.CreatedAt))
+	d.Set("updated_at", formatTimestamp(repo.UpdatedAt))
+	d.Set("last_commit_at", formatTimestamp(repo.LastCommitAt))
+
+	if repo.VCS == "subversion" {
+		// Subversion repositories have no default branch, so we leave
+		// whatever is in the configuration alone; the diff is suppressed.
+		d.Set("svn_revision", repo.Revision.Int())
+		d.Set("ssh_url", "")
+		d.Set("https_url", repo.URL)
+	} else {
+		d.Set("default_git_branch", repo.DefaultGitBranch)
+		d.Set("ssh_url", repo.URL)
+		d.Set("https_url", repo.HTTPSURL)
+		d.Set("svn_revision", 0)
+	}
 
 	return nil
 }
 
-func RenameRepository(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
-
-	req := &RepositoryWrap{
-		Repository: Repository{
-			Name: d.Get("name").(string),
-		},
-	}
+func UpdateRepository(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+	return updateRepository(ctx, client, d)
+}
 
-	err := client.Put([]string{"repositories", d.Id(), "rename"}, req, nil)
+func updateRepository(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func UpdateRepository(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
-
 	d.Partial(true)
 
 	if d.HasChange("name") {
 		// Renaming has its own special operation.
-		err := RenameRepository(d, meta)
+		err := client.Repositories.Rename(ctx, id, d.Get("name").(string))
 		if err != nil {
 			return err
 		}
@@ -147,40 +218,49 @@ func UpdateRepository(d *schema.ResourceData, meta interface{}) error {
 		d.SetPartial("name")
 	}
 
-	req := &RepositoryWrap{
-		Repository: Repository{
-			Title:            d.Get("title").(string),
-			ColorLabel:       d.Get("color_label").(string),
-			DefaultGitBranch: d.Get("default_git_branch").(string),
-		},
+	repo := &api.Repository{
+		Title:      d.Get("title").(string),
+		ColorLabel: d.Get("color_label").(string),
 	}
 
-	err := client.Put([]string{"repositories", d.Id()}, req, nil)
+	// Beanstalk rejects a default branch for Subversion repositories,
+	// so it's only sent for git.
+	if d.Get("vcs").(string) == "git" {
+		repo.DefaultGitBranch = d.Get("default_git_branch").(string)
+	}
+
+	err = client.Repositories.Update(ctx, id, repo)
 	if err != nil {
 		return err
 	}
 
 	d.Partial(false)
 
-	return ReadRepository(d, meta)
+	return readRepository(ctx, client, d)
 }
 
 func DeleteRepository(d *schema.ResourceData, meta interface{}) error {
 	return fmt.Errorf("Beanstalk does not allow repositories to be deleted via its API. Delete this repository via the UI and run 'terraform refresh' to make Terraform notice it's gone.")
 }
 
-type Repository struct {
-	ID                 int    `json:"id,omitempty"`
-	Title              string `json:"title"`
-	Name               string `json:"name"`
-	ColorLabel         string `json:"color_label,omitempty"`
-	DefaultGitBranch   string `json:"default_branch,omitempty"`
-	TypeID             string `json:"type_id,omitempty"`
-	VCS                string `json:"vcs,omitempty"`
-	CreateSVNStructure bool   `json:"create_structure"`
-	URL                string `json:"repository_url,omitempty"`
+// Whether the standard Subversion layout was requested isn't recorded by
+// Beanstalk, so create_svn_structure matters only when the repository is
+// created. Changes to it afterwards, including the difference between
+// the configuration and the unset value of an imported repository, are
+// ignored rather than planning a replacement that Delete would refuse.
+// When a change of vcs replaces the repository anyway, the setting is kept
+// so that the new repository is created as configured.
+func suppressCreateSVNStructure(k, old, new string, d *schema.ResourceData) bool {
+	if d.Id() != "" && !d.HasChange("vcs") {
+		return true
+	}
+	return d.Get("vcs").(string) != "subversion"
 }
 
-type RepositoryWrap struct {
-	Repository Repository `json:"repository"`
+// suppressUnlessVCS returns a DiffSuppressFunc that hides changes to
+// an attribute that only has meaning for the given version control system.
+func suppressUnlessVCS(vcs string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		return d.Get("vcs").(string) != vcs
+	}
 }
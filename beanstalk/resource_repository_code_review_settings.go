@@ -1,10 +1,12 @@
 package beanstalk
 
 import (
+	"context"
 	"log"
 	"strconv"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
 func resourceRepositoryCodeReviewSettings() *schema.Resource {
@@ -14,6 +16,12 @@ func resourceRepositoryCodeReviewSettings() *schema.Resource {
 		Update: UpdateRepositoryCodeReviewSettings,
 		Delete: DeleteRepositoryCodeReviewSettings,
 
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: defaultResourceTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"repository_id": &schema.Schema{
 				Type:     schema.TypeInt,
@@ -60,27 +68,37 @@ func resourceRepositoryCodeReviewSettings() *schema.Resource {
 }
 
 func CreateRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
 	id := strconv.Itoa(d.Get("repository_id").(int))
 	d.SetId(id)
-	return UpdateRepositoryCodeReviewSettings(d, meta)
+	return updateRepositoryCodeReviewSettings(ctx, client, d)
 }
 
 func ReadRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readRepositoryCodeReviewSettings(ctx, client, d)
+}
 
-	res := &RepositoryCodeReview{}
+func readRepositoryCodeReviewSettings(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	repositoryId, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
 
-	err := client.Get([]string{d.Id(), "code_reviews", "settings"}, nil, res)
+	res, err := client.CodeReviews.GetSettings(ctx, repositoryId)
 	if err != nil {
-		if _, ok := err.(*NotFoundError); ok {
+		if api.IsNotFound(err) {
 			d.SetId("")
 			d.Set("id", "")
 			return nil
-		} else {
-			return err
 		}
+		return err
 	}
 
+	d.Set("repository_id", repositoryId)
 	d.Set("unanimous_approval", res.UnanimousApproval)
 	d.Set("auto_reopen", res.AutoReopen)
 
@@ -109,7 +127,16 @@ func ReadRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{})
 }
 
 func UpdateRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+	return updateRepositoryCodeReviewSettings(ctx, client, d)
+}
+
+func updateRepositoryCodeReviewSettings(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	repositoryId, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
 
 	sliceToInts := func(in []interface{}) []int {
 		ret := make([]int, len(in))
@@ -123,15 +150,13 @@ func UpdateRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{}
 	watcherUserIds := sliceToInts(d.Get("default_watching_user_ids").([]interface{}))
 	watcherTeamIds := sliceToInts(d.Get("default_watching_team_ids").([]interface{}))
 
-	req := &RepositoryCodeReview{
+	return client.CodeReviews.UpdateSettings(ctx, repositoryId, &api.CodeReviewSettingsWrite{
 		UnanimousApproval:      d.Get("unanimous_approval").(bool),
 		AutoReopen:             d.Get("auto_reopen").(bool),
 		DefaultAssigneeUserIDs: assigneeUserIds,
 		DefaultWatcherUserIDs:  watcherUserIds,
 		DefaultWatcherTeamIDs:  watcherTeamIds,
-	}
-
-	return client.Put([]string{d.Id(), "code_reviews", "settings"}, req, nil)
+	})
 }
 
 func DeleteRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{}) error {
@@ -139,21 +164,3 @@ func DeleteRepositoryCodeReviewSettings(d *schema.ResourceData, meta interface{}
 	// so we don't need to take any action here.
 	return nil
 }
-
-type RepositoryCodeReview struct {
-	UnanimousApproval      bool                          `json:"unanimous_approval"`
-	AutoReopen             bool                          `json:"auto_reopen"`
-	DefaultWatchers        []RepositoryCodeReviewWatcher `json:"default_watchers,omitempty"`
-	DefaultAssignees       []RepositoryCodeReviewWatcher `json:"default_assignees,omitempty"`
-	DefaultAssigneeUserIDs []int                         `json:"default_assignees"`
-	DefaultWatcherUserIDs  []int                         `json:"default_watchers_user_ids"`
-	DefaultWatcherTeamIDs  []int                         `json:"default_watchers_team_ids"`
-}
-
-type RepositoryCodeReviewWatcher struct {
-	ID       int    `json:"id"`
-	Type     string `json:"type"`
-	Name     string `json:"name"`
-	Username string `json:"login"`
-	Email    string `json:"email"`
-}
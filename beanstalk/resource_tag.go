@@ -0,0 +1,123 @@
+package beanstalk
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func resourceTag() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateTag,
+		Read:   ReadTag,
+		Delete: DeleteTag,
+
+		Timeouts: defaultResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"ref": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"message": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"revision": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateTag(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	repositoryId := d.Get("repository_id").(int)
+	name := d.Get("name").(string)
+
+	err := client.Tags.Create(ctx, repositoryId, &api.Tag{
+		Name:    name,
+		Ref:     d.Get("ref").(string),
+		Message: d.Get("message").(string),
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(repositoryRefId(repositoryId, name))
+
+	return readTag(ctx, client, d)
+}
+
+func ReadTag(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readTag(ctx, client, d)
+}
+
+func readTag(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	repositoryId, name, err := parseRepositoryRefId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	tag, err := client.Tags.Get(ctx, repositoryId, name)
+	if err != nil {
+		if api.IsNotFound(err) {
+			// Either the tag or its whole repository was deleted
+			// outside of Terraform.
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("name", tag.Name)
+	d.Set("revision", tag.Revision)
+
+	// Lightweight tags have no message, and the listing doesn't always
+	// include it, so the configured message is kept unless there is one.
+	if tag.Message != "" {
+		d.Set("message", tag.Message)
+	}
+
+	return nil
+}
+
+func DeleteTag(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	repositoryId, name, err := parseRepositoryRefId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = client.Tags.Delete(ctx, repositoryId, name)
+	if err != nil && !api.IsNotFound(err) {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
 func resourceTeam() *schema.Resource {
@@ -15,6 +16,12 @@ func resourceTeam() *schema.Resource {
 		Update: UpdateTeam,
 		Delete: DeleteTeam,
 
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: defaultResourceTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -22,9 +29,10 @@ func resourceTeam() *schema.Resource {
 			},
 
 			"color_label": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "white",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "white",
+				ValidateFunc: validateColorLabel,
 			},
 
 			"user_ids": &schema.Schema{
@@ -76,42 +84,48 @@ func resourceTeam() *schema.Resource {
 }
 
 func CreateTeam(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	req := teamFromResourceData(d)
-
-	res := &TeamReadWrap{}
-
-	err := client.Post([]string{"teams"}, req, res)
+	team, err := client.Teams.Create(ctx, teamFromResourceData(d))
 	if err != nil {
 		return err
 	}
 
-	updateResourceDataFromTeam(&res.Team, d)
+	updateResourceDataFromTeam(team, d)
 
 	return nil
 }
 
 func UpdateTeam(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	req := teamFromResourceData(d)
-
-	res := &TeamReadWrap{}
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
 
-	err := client.Put([]string{"teams", d.Id()}, req, res)
+	team, err := client.Teams.Update(ctx, id, teamFromResourceData(d))
 	if err != nil {
 		return err
 	}
 
-	updateResourceDataFromTeam(&res.Team, d)
+	updateResourceDataFromTeam(team, d)
 
 	return nil
 }
 
 func DeleteTeam(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
-	err := client.Delete([]string{"teams", d.Id()})
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = client.Teams.Delete(ctx, id)
 	if err == nil {
 		d.SetId("")
 	}
@@ -119,35 +133,40 @@ func DeleteTeam(d *schema.ResourceData, meta interface{}) error {
 }
 
 func ReadTeam(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-	var res TeamReadWrap
-	err := client.Get([]string{"teams", d.Id()}, nil, &res)
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
-		if _, ok := err.(*NotFoundError); ok {
+		return err
+	}
+
+	team, err := client.Teams.Get(ctx, id)
+	if err != nil {
+		if api.IsNotFound(err) {
 			d.SetId("")
 			return nil
 		}
 		return err
 	}
 
-	updateResourceDataFromTeam(&res.Team, d)
+	updateResourceDataFromTeam(team, d)
 
 	return nil
 }
 
-func teamFromResourceData(d *schema.ResourceData) *TeamWrite {
+func teamFromResourceData(d *schema.ResourceData) *api.TeamWrite {
 	userIdsSet := d.Get("user_ids").(*schema.Set)
 	userIdsI := userIdsSet.List()
 
 	permissionsesSet := d.Get("repository_permissions").(*schema.Set)
 	permissionsesI := permissionsesSet.List()
 
-	team := &TeamWrite{
+	team := &api.TeamWrite{
 		Name:        d.Get("name").(string),
 		ColorLabel:  d.Get("color_label").(string),
 		UserIDs:     make([]int, len(userIdsI)),
-		Permissions: map[string]TeamRepositoryPermissionsWrite{},
+		Permissions: map[string]api.TeamRepositoryPermissionsWrite{},
 	}
 
 	for i, userIdI := range userIdsI {
@@ -158,7 +177,7 @@ func teamFromResourceData(d *schema.ResourceData) *TeamWrite {
 		permissionsMap := permissionsI.(map[string]interface{})
 		repositoryId := permissionsMap["repository_id"].(int)
 
-		team.Permissions[strconv.Itoa(repositoryId)] = TeamRepositoryPermissionsWrite{
+		team.Permissions[strconv.Itoa(repositoryId)] = api.TeamRepositoryPermissionsWrite{
 			CanWrite:                permissionsMap["can_write"].(bool),
 			CanDeploy:               permissionsMap["can_deploy"].(bool),
 			CanConfigureDeployments: permissionsMap["can_configure_deployments"].(bool),
@@ -168,7 +187,7 @@ func teamFromResourceData(d *schema.ResourceData) *TeamWrite {
 	return team
 }
 
-func updateResourceDataFromTeam(team *TeamRead, d *schema.ResourceData) {
+func updateResourceDataFromTeam(team *api.TeamRead, d *schema.ResourceData) {
 	d.SetId(strconv.Itoa(team.ID))
 	d.Set("id", team.ID)
 	d.Set("name", team.Name)
@@ -204,37 +223,3 @@ func hashRepositoryPermissions(v interface{}) int {
 	)
 	return hashcode.String(hashInput)
 }
-
-type TeamWrite struct {
-	ID          int                                       `json:"id,omitempty"`
-	Name        string                                    `json:"name"`
-	ColorLabel  string                                    `json:"color_label,omitempty"`
-	UserIDs     []int                                     `json:"users"`
-	Permissions map[string]TeamRepositoryPermissionsWrite `json:"permissions"`
-}
-
-type TeamRead struct {
-	ID          int                             `json:"id,omitempty"`
-	Name        string                          `json:"name"`
-	ColorLabel  string                          `json:"color_label,omitempty"`
-	Users       []User                          `json:"users"`
-	Permissions []TeamRepositoryPermissionsRead `json:"permissions"`
-}
-
-type TeamReadWrap struct {
-	Team TeamRead `json:"team"`
-}
-
-type TeamRepositoryPermissionsWrite struct {
-	CanWrite                bool `json:"write"`
-	CanDeploy               bool `json:"deploy"`
-	CanConfigureDeployments bool `json:"configure_deployments"`
-}
-
-type TeamRepositoryPermissionsRead struct {
-	RepositoryID            int    `json:"repository_id"`
-	RepositoryTitle         string `json:"repository_title"`
-	CanWrite                bool   `json:"write"`
-	CanDeploy               bool   `json:"deploy"`
-	CanConfigureDeployments bool   `json:"configure_deployments"`
-}
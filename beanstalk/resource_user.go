@@ -1,10 +1,14 @@
 package beanstalk
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
 func resourceUser() *schema.Resource {
@@ -14,6 +18,14 @@ func resourceUser() *schema.Resource {
 		Update: UpdateUser,
 		Delete: DeleteUser,
 
+		CustomizeDiff: customizeUserDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: defaultResourceTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"username": &schema.Schema{
 				Type:     schema.TypeString,
@@ -21,13 +33,18 @@ func resourceUser() *schema.Resource {
 			},
 
 			"name": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ConflictsWith:    []string{"first_name", "last_name"},
+				DiffSuppressFunc: suppressNameNormalization,
 			},
 
 			"email": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateEmail,
+				DiffSuppressFunc: suppressEmailCase,
 			},
 
 			"account_admin": &schema.Schema{
@@ -37,9 +54,11 @@ func resourceUser() *schema.Resource {
 			},
 
 			"timezone": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "London",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateFunc:     validateTimezone,
+				DiffSuppressFunc: suppressTimezoneAlias,
 			},
 
 			"id": &schema.Schema{
@@ -47,12 +66,60 @@ func resourceUser() *schema.Resource {
 				Computed: true,
 			},
 
+			"account_owner": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
 			"first_name": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				DiffSuppressFunc: suppressNameNormalization,
+			},
+
+			"last_name": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				DiffSuppressFunc: suppressNameNormalization,
+			},
+
+			"on_destroy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "delete",
+				ValidateFunc: validateUserOnDestroy,
+			},
+
+			"resend_invitation": &schema.Schema{
 				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"invitation_expiry_days": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+
+			"resend_expired_invitation": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"invitation_id": &schema.Schema{
+				Type:     schema.TypeInt,
 				Computed: true,
 			},
 
-			"last_name": &schema.Schema{
+			"invitation_status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"invitation_sent_at": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
 			},
@@ -61,148 +128,342 @@ func resourceUser() *schema.Resource {
 }
 
 func CreateUser(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
 	email := d.Get("email").(string)
 
-	req := &InvitationCreateRequestWrap{
-		InvitationCreateRequest{
-			User{
-				Name:  d.Get("name").(string),
-				Email: email,
-			},
-		},
-	}
-	res := &InvitationWrap{}
-
-	err := client.Post([]string{"invitations"}, req, res)
+	invitation, err := client.Invitations.Create(ctx, &api.User{
+		Name:  userFullName(d),
+		Email: email,
+	})
 	if err != nil {
 		return err
 	}
 
-	// By creating an invitation we also created a user, but the
-	// Beanstalk API doesn't give us the id of the user in the
-	// response so we have to go hunt for it in the user list,
-	// using the email address (which is guaranteed unique).
-
-	id := 0
-	var ures []UserWrap
-	pathParts := []string{"users"}
-	queryArgs := map[string]string{
-		"per_page": "50",
-		"page":     "0",
-	}
-	pageIdx := 1
-Pages:
-	for id == 0 {
-		queryArgs["page"] = strconv.Itoa(pageIdx)
-		err := client.Get(pathParts, queryArgs, &ures)
+	// By creating an invitation we also created a user. The invitation
+	// normally refers to it, but if not then we have to go hunt for it
+	// in the user list, using the email address (which is guaranteed
+	// unique).
+	userId := invitation.UserID
+	if userId == 0 {
+		user, err := client.Users.FindByEmail(ctx, email)
 		if err != nil {
-			return err
-		}
-		if len(ures) == 0 {
-			return fmt.Errorf("invited user %v is not in user list", email)
-		}
-		for _, userWrap := range ures {
-			if userWrap.User.Email == email {
-				id = userWrap.User.ID
-				break Pages
+			if api.IsNotFound(err) {
+				return fmt.Errorf("invited user %v is not in user list", email)
 			}
+			return err
 		}
-
-		pageIdx++
+		userId = user.ID
 	}
 
-	d.SetId(strconv.Itoa(id))
-	d.Set("id", id)
+	d.SetId(strconv.Itoa(userId))
+	d.Set("id", userId)
+	d.Set("invitation_id", invitation.ID)
+	d.Set("invitation_status", "pending")
+	d.Set("invitation_sent_at", time.Now().UTC().Format(time.RFC3339))
 
-	return UpdateUser(d, meta)
+	return updateUser(ctx, client, d)
 }
 
 func ReadUser(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readUser(ctx, client, d)
+}
 
-	var res UserWrap
-	err := client.Get([]string{"users", d.Id()}, nil, &res)
+func readUser(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
-		if _, ok := err.(*NotFoundError); ok {
+		return err
+	}
+
+	user, err := client.Users.Get(ctx, id)
+	if err != nil {
+		if api.IsNotFound(err) {
 			d.SetId("")
 			return nil
 		}
 		return err
 	}
 
-	d.Set("username", res.User.Username)
-	d.Set("email", res.User.Email)
-	d.Set("name", res.User.Name)
-	d.Set("account_admin", res.User.IsAccountAdmin)
-	d.Set("timezone", res.User.Timezone)
-	d.Set("first_name", res.User.FirstName)
-	d.Set("last_name", res.User.LastName)
+	d.Set("username", user.Username)
+	d.Set("email", user.Email)
+	d.Set("name", user.Name)
+	d.Set("account_admin", user.IsAccountAdmin)
+	d.Set("account_owner", user.IsAccountOwner)
+	d.Set("timezone", user.Timezone)
+	d.Set("first_name", user.FirstName)
+	d.Set("last_name", user.LastName)
 
-	return nil
+	return refreshInvitationStatus(ctx, client, d)
 }
 
 func UpdateUser(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
-
-	userWrap := &UserWrap{
-		User: User{
-			Username:       d.Get("username").(string),
-			Email:          d.Get("email").(string),
-			Name:           d.Get("name").(string),
-			IsAccountAdmin: d.Get("account_admin").(bool),
-			Timezone:       d.Get("timezone").(string),
-		},
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+	return updateUser(ctx, client, d)
+}
+
+func updateUser(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if !d.IsNewResource() {
+		err := resendInvitationIfNeeded(ctx, client, id, d)
+		if err != nil {
+			return err
+		}
 	}
 
-	err := client.Put([]string{"users", d.Id()}, userWrap, nil)
+	user := &api.User{
+		Username:       d.Get("username").(string),
+		Email:          d.Get("email").(string),
+		IsAccountAdmin: d.Get("account_admin").(bool),
+		Timezone:       railsTimezoneName(d.Get("timezone").(string)),
+	}
+	if d.HasChange("first_name") || d.HasChange("last_name") {
+		user.FirstName = d.Get("first_name").(string)
+		user.LastName = d.Get("last_name").(string)
+	} else {
+		user.Name = userFullName(d)
+	}
+
+	err = client.Users.Update(ctx, id, user)
 	if err != nil {
 		return err
 	}
 
-	return ReadUser(d, meta)
+	return readUser(ctx, client, d)
 }
 
 func DeleteUser(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*Client)
-	err := client.Delete([]string{"users", d.Id()})
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	onDestroy := d.Get("on_destroy").(string)
+
+	// The owner can't be deleted or stripped of their access, so we
+	// check before doing anything rather than leave the job half done.
+	if onDestroy != "abandon" && d.Get("account_owner").(bool) {
+		return fmt.Errorf(
+			"user %s is the account owner and so can't be deleted or deactivated; transfer ownership of the account to another user first, using beanstalk_account_ownership_transfer",
+			d.Get("username").(string),
+		)
+	}
+
+	switch onDestroy {
+	case "abandon":
+		// The user is left exactly as it is.
+		err = nil
+	case "deactivate":
+		err = DeactivateUser(ctx, client, id)
+	default:
+		err = client.Users.Delete(ctx, id)
+	}
 	if err == nil {
 		d.SetId("")
 	}
 	return err
 }
 
-type Invitation struct {
-	ID    int    `json:"id,omitempty"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+// The name can be given either whole or as separate first and last names.
+// Beanstalk stores both forms, so whichever is given is sent and the other
+// is read back.
+func userFullName(d *schema.ResourceData) string {
+	if name := d.Get("name").(string); name != "" && !d.HasChange("first_name") && !d.HasChange("last_name") {
+		return name
+	}
+	return strings.TrimSpace(d.Get("first_name").(string) + " " + d.Get("last_name").(string))
+}
+
+// Email addresses are compared without regard to case, so a change of
+// case alone is not worth a diff.
+func suppressEmailCase(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
 }
 
-type InvitationWrap struct {
-	Invitation Invitation `json:"invitation"`
+// The timezone may be configured by its IANA name, but Beanstalk always
+// reports the Rails name, so the two are not worth a diff when they
+// describe the same zone. Several Rails names can share a zone, and so
+// any of them is accepted for an IANA name.
+func suppressTimezoneAlias(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	_, oldIsRails := railsTimezones[old]
+	_, newIsRails := railsTimezones[new]
+	if oldIsRails && newIsRails {
+		return false
+	}
+	return ianaTimezoneName(old) == ianaTimezoneName(new)
 }
 
-type InvitationCreateRequest struct {
-	User User `json:"user"`
+// Beanstalk tidies up names as it stores them, so differences only in
+// whitespace or case are not worth a diff.
+func suppressNameNormalization(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeName(old) == normalizeName(new)
 }
 
-type InvitationCreateRequestWrap struct {
-	Invitation InvitationCreateRequest `json:"invitation"`
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
 }
 
-type User struct {
-	ID             int    `json:"id,omitempty"`
-	Username       string `json:"login"`
-	Email          string `json:"email"`
-	Name           string `json:"name"`
-	Timezone       string `json:"timezone"`
-	IsAccountAdmin bool   `json:"admin"`
-	IsAccountOwner bool   `json:"owner"`
-	FirstName      string `json:"first_name"`
-	LastName       string `json:"last_name"`
+// DeactivateUser removes all of a user's access to the account's
+// repositories, but keeps the user itself so that their commits and
+// comments remain attributed to them. The API has no way to prevent the
+// user from logging in, but once deactivated they can see nothing.
+func DeactivateUser(ctx context.Context, client *api.Client, id int) error {
+	user, err := client.Users.Get(ctx, id)
+	if err != nil {
+		if api.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if user.IsAccountAdmin {
+		user.IsAccountAdmin = false
+		err := client.Users.Update(ctx, id, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	permissions, err := client.Permissions.List(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		err := client.Permissions.Delete(ctx, permission.ID)
+		if err != nil && !api.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// The teams are collected first, rather than updated while listing
+	// them, so that the changes can't upset the paging.
+	teams := []*api.TeamWrite{}
+	err = client.Teams.Each(ctx, func(team *api.TeamRead) (bool, error) {
+		write := team.ToWrite()
+		remaining := make([]int, 0, len(write.UserIDs))
+		for _, userId := range write.UserIDs {
+			if userId != id {
+				remaining = append(remaining, userId)
+			}
+		}
+		if len(remaining) != len(write.UserIDs) {
+			write.UserIDs = remaining
+			teams = append(teams, write)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		_, err := client.Teams.Update(ctx, team.ID, team)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-type UserWrap struct {
-	User User `json:"user"`
+// Invitations are tracked by id so that the user's email address can
+// change without losing track of them. Once accepted, an invitation is
+// no longer checked; users that were imported have no known invitation
+// and so no status.
+func refreshInvitationStatus(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	invitationId := d.Get("invitation_id").(int)
+	if invitationId == 0 || d.Get("invitation_status").(string) == "accepted" {
+		return nil
+	}
+
+	_, err := client.Invitations.Get(ctx, invitationId)
+	if err != nil {
+		if api.IsNotFound(err) {
+			d.Set("invitation_status", "accepted")
+			return nil
+		}
+		return err
+	}
+
+	status := "pending"
+	expiryDays := d.Get("invitation_expiry_days").(int)
+	if expiryDays > 0 {
+		sentAt, err := time.Parse(time.RFC3339, d.Get("invitation_sent_at").(string))
+		if err == nil && time.Since(sentAt) > time.Duration(expiryDays)*24*time.Hour {
+			status = "expired"
+		}
+	}
+	d.Set("invitation_status", status)
+
+	return nil
+}
+
+func resendInvitationIfNeeded(ctx context.Context, client *api.Client, id int, d *schema.ResourceData) error {
+	// The status may have been marked as changing by customizeUserDiff,
+	// so we look at its value before this change.
+	statusI, _ := d.GetChange("invitation_status")
+	status := statusI.(string)
+	if status != "pending" && status != "expired" {
+		return nil
+	}
+
+	resend := d.HasChange("resend_invitation") ||
+		(status == "expired" && d.Get("resend_expired_invitation").(bool))
+	if !resend {
+		return nil
+	}
+
+	err := client.Invitations.Resend(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	d.Set("invitation_status", "pending")
+	d.Set("invitation_sent_at", time.Now().UTC().Format(time.RFC3339))
+	return nil
+}
+
+func customizeUserDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		// A new user's name can be given either way, but must be given.
+		if !d.NewValueKnown("name") || !d.NewValueKnown("first_name") || !d.NewValueKnown("last_name") {
+			return nil
+		}
+		hasName := d.Get("name").(string) != ""
+		hasFirstLast := d.Get("first_name").(string) != "" && d.Get("last_name").(string) != ""
+		if !hasName && !hasFirstLast {
+			return fmt.Errorf("either name or both first_name and last_name must be set")
+		}
+		return nil
+	}
+
+	if d.Get("account_owner").(bool) && d.HasChange("account_admin") && !d.Get("account_admin").(bool) {
+		return fmt.Errorf(
+			"user %s is the account owner and so can't stop being an account admin; transfer ownership of the account to another user first, using beanstalk_account_ownership_transfer",
+			d.Get("username").(string),
+		)
+	}
+
+	// An expired invitation that should be resent must produce a diff,
+	// or else Terraform would never call UpdateUser to resend it.
+	if d.Get("invitation_status").(string) == "expired" && d.Get("resend_expired_invitation").(bool) {
+		if err := d.SetNewComputed("invitation_status"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("invitation_sent_at")
+	}
+
+	return nil
 }
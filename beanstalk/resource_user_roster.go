@@ -0,0 +1,598 @@
+package beanstalk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+// The user roster manages a whole set of users as a single resource, for
+// rosters that are generated from some other system such as an identity
+// provider. Unlike the individual user resource, a failure to manage one
+// user doesn't prevent the others from being managed; instead it is
+// reported in the roster's errors attribute and retried on the next run.
+// Users that the roster finds already in Beanstalk are adopted, but only
+// those it invited itself are deleted or deactivated when removed.
+
+func resourceUserRoster() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateUserRoster,
+		Read:   ReadUserRoster,
+		Update: UpdateUserRoster,
+		Delete: DeleteUserRoster,
+
+		Timeouts: defaultResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"user": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"login": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"email": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateEmail,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"team_ids": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeInt,
+							},
+							Set: func(v interface{}) int {
+								return v.(int)
+							},
+						},
+					},
+				},
+			},
+
+			"on_destroy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "delete",
+				ValidateFunc: validateUserOnDestroy,
+			},
+
+			"user_ids": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+
+			"errors": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+
+			"invited_user_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Set: func(v interface{}) int {
+					return v.(int)
+				},
+			},
+		},
+	}
+}
+
+// rosterEntry is one user as described in the roster configuration.
+type rosterEntry struct {
+	Login   string
+	Email   string
+	Name    string
+	TeamIDs []int
+}
+
+func rosterEntries(set *schema.Set) map[string]*rosterEntry {
+	entries := map[string]*rosterEntry{}
+	for _, itemI := range set.List() {
+		item := itemI.(map[string]interface{})
+		entry := &rosterEntry{
+			Login: item["login"].(string),
+			Email: item["email"].(string),
+			Name:  item["name"].(string),
+		}
+		for _, teamIdI := range item["team_ids"].(*schema.Set).List() {
+			entry.TeamIDs = append(entry.TeamIDs, teamIdI.(int))
+		}
+		entries[strings.ToLower(entry.Login)] = entry
+	}
+	return entries
+}
+
+// rosterUserIds returns the ids of the users that the roster currently
+// manages, keyed by lowercase login.
+func rosterUserIds(d *schema.ResourceData) map[string]int {
+	ids := map[string]int{}
+	for login, idI := range d.Get("user_ids").(map[string]interface{}) {
+		id, err := strconv.Atoi(idI.(string))
+		if err == nil {
+			ids[login] = id
+		}
+	}
+	return ids
+}
+
+func setRosterUserIds(d *schema.ResourceData, ids map[string]int) {
+	raw := map[string]interface{}{}
+	for login, id := range ids {
+		raw[login] = strconv.Itoa(id)
+	}
+	d.Set("user_ids", raw)
+}
+
+// rosterInvitedIds returns the ids of the users that the roster invited,
+// as opposed to those it adopted.
+func rosterInvitedIds(d *schema.ResourceData) map[int]bool {
+	ids := map[int]bool{}
+	for _, idI := range d.Get("invited_user_ids").(*schema.Set).List() {
+		ids[idI.(int)] = true
+	}
+	return ids
+}
+
+// setRosterInvitedIds records which of the given managed users the roster
+// invited, forgetting any that it no longer manages.
+func setRosterInvitedIds(d *schema.ResourceData, invited map[int]bool, ids map[string]int) {
+	raw := []interface{}{}
+	for _, id := range ids {
+		if invited[id] {
+			raw = append(raw, id)
+		}
+	}
+	d.Set("invited_user_ids", raw)
+}
+
+func CreateUserRoster(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	d.SetId(resource.UniqueId())
+	return updateUserRoster(ctx, client, d)
+}
+
+func ReadUserRoster(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutRead)
+	defer cancel()
+	return readUserRoster(ctx, client, d)
+}
+
+func readUserRoster(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	ids := rosterUserIds(d)
+	configured := rosterEntries(d.Get("user").(*schema.Set))
+
+	teamsByUser := map[int]map[int]bool{}
+	err := client.Teams.Each(ctx, func(team *api.TeamRead) (bool, error) {
+		for _, user := range team.Users {
+			if teamsByUser[user.ID] == nil {
+				teamsByUser[user.ID] = map[int]bool{}
+			}
+			teamsByUser[user.ID][team.ID] = true
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	users := []interface{}{}
+	for login, id := range ids {
+		user, err := client.Users.Get(ctx, id)
+		if err != nil {
+			if api.IsNotFound(err) {
+				delete(ids, login)
+				continue
+			}
+			return err
+		}
+
+		entry := map[string]interface{}{
+			"login": user.Username,
+			"email": user.Email,
+			"name":  user.Name,
+		}
+
+		// The same leniency as for individual users applies to email
+		// addresses and names, and the user's membership of teams not
+		// listed in the roster is none of the roster's business.
+		teamIds := []interface{}{}
+		if config, ok := configured[login]; ok {
+			if strings.EqualFold(config.Email, user.Email) {
+				entry["email"] = config.Email
+			}
+			if normalizeName(config.Name) == normalizeName(user.Name) {
+				entry["name"] = config.Name
+			}
+			for _, teamId := range config.TeamIDs {
+				if teamsByUser[id][teamId] {
+					teamIds = append(teamIds, teamId)
+				}
+			}
+		}
+		entry["team_ids"] = teamIds
+
+		users = append(users, entry)
+	}
+
+	d.Set("user", users)
+	setRosterUserIds(d, ids)
+	setRosterInvitedIds(d, rosterInvitedIds(d), ids)
+
+	return nil
+}
+
+func UpdateUserRoster(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+	return updateUserRoster(ctx, client, d)
+}
+
+func updateUserRoster(ctx context.Context, client *api.Client, d *schema.ResourceData) error {
+	oldUsersI, newUsersI := d.GetChange("user")
+	previous := rosterEntries(oldUsersI.(*schema.Set))
+	desired := rosterEntries(newUsersI.(*schema.Set))
+
+	r := &rosterReconciler{
+		client:    client,
+		ids:       rosterUserIds(d),
+		invited:   rosterInvitedIds(d),
+		errors:    map[string]string{},
+		onDestroy: d.Get("on_destroy").(string),
+	}
+
+	err := r.reconcileUsers(ctx, desired)
+	if err != nil {
+		return err
+	}
+	err = r.reconcileTeams(ctx, previous, desired)
+	if err != nil {
+		return err
+	}
+
+	setRosterUserIds(d, r.ids)
+	setRosterInvitedIds(d, r.invited, r.ids)
+	errors := map[string]interface{}{}
+	for login, message := range r.errors {
+		log.Printf("[WARN] Beanstalk user roster could not manage %s: %s", login, message)
+		errors[login] = message
+	}
+	d.Set("errors", errors)
+
+	return readUserRoster(ctx, client, d)
+}
+
+func DeleteUserRoster(d *schema.ResourceData, meta interface{}) error {
+	client, ctx, cancel := clientContext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	r := &rosterReconciler{
+		client:    client,
+		ids:       rosterUserIds(d),
+		invited:   rosterInvitedIds(d),
+		errors:    map[string]string{},
+		onDestroy: d.Get("on_destroy").(string),
+	}
+
+	err := r.reconcileUsers(ctx, map[string]*rosterEntry{})
+	if err != nil {
+		return err
+	}
+
+	// Unlike when updating, a user that couldn't be removed has to fail
+	// the whole operation, or else Terraform would forget about them.
+	if len(r.errors) > 0 {
+		setRosterUserIds(d, r.ids)
+		setRosterInvitedIds(d, r.invited, r.ids)
+		messages := make([]string, 0, len(r.errors))
+		for login, message := range r.errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", login, message))
+		}
+		sort.Strings(messages)
+		return fmt.Errorf("could not remove some users from Beanstalk:\n%s", strings.Join(messages, "\n"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// rosterReconciler brings the users in Beanstalk in line with a roster,
+// recording a failure to manage any one user rather than giving up.
+type rosterReconciler struct {
+	client    *api.Client
+	ids       map[string]int
+	invited   map[int]bool
+	errors    map[string]string
+	onDestroy string
+}
+
+func (r *rosterReconciler) fail(login string, err error) {
+	if existing, ok := r.errors[login]; ok {
+		r.errors[login] = existing + "; " + err.Error()
+		return
+	}
+	r.errors[login] = err.Error()
+}
+
+func (r *rosterReconciler) reconcileUsers(ctx context.Context, desired map[string]*rosterEntry) error {
+	// The whole user list is fetched once, rather than looking up each
+	// user separately. Errors here affect every user, so they do fail
+	// the whole operation.
+	byId := map[int]*api.User{}
+	byLogin := map[string]*api.User{}
+	byEmail := map[string]*api.User{}
+	err := r.client.Users.Each(ctx, func(user *api.User) (bool, error) {
+		u := *user
+		byId[u.ID] = &u
+		byLogin[strings.ToLower(u.Username)] = &u
+		byEmail[strings.ToLower(u.Email)] = &u
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	desiredByEmail := map[string]string{}
+	for login, entry := range desired {
+		desiredByEmail[strings.ToLower(entry.Email)] = login
+	}
+
+	// Users that are no longer in the roster are removed first, so that
+	// their logins and email addresses are free for any new users. A
+	// user whose login has changed is recognized by their email address,
+	// so that they are renamed rather than replaced.
+	for login, id := range r.ids {
+		if _, ok := desired[login]; ok {
+			continue
+		}
+		user, ok := byId[id]
+		if !ok {
+			delete(r.ids, login)
+			continue
+		}
+		if newLogin, ok := desiredByEmail[strings.ToLower(user.Email)]; ok {
+			if _, managed := r.ids[newLogin]; !managed {
+				r.ids[newLogin] = id
+				delete(r.ids, login)
+				continue
+			}
+		}
+		if err := r.removeUser(ctx, user); err != nil {
+			r.fail(login, err)
+			continue
+		}
+		delete(r.ids, login)
+	}
+
+	// Users that exist already, whether or not the roster created them,
+	// are adopted. The rest are invited, and then all are updated with
+	// their full details. Only the invited users are recorded as such,
+	// so that removing an adopted user doesn't delete an account that
+	// the roster didn't create.
+	toUpdate := map[string]*rosterEntry{}
+	invitations := map[string]*api.Invitation{}
+	for login, entry := range desired {
+		if id, ok := r.ids[login]; ok && byId[id] != nil {
+			toUpdate[login] = entry
+			continue
+		}
+		if user, ok := byLogin[login]; ok {
+			r.ids[login] = user.ID
+			toUpdate[login] = entry
+			continue
+		}
+		if user, ok := byEmail[strings.ToLower(entry.Email)]; ok {
+			r.ids[login] = user.ID
+			toUpdate[login] = entry
+			continue
+		}
+
+		invitation, err := r.client.Invitations.Create(ctx, &api.User{
+			Name:  entry.Name,
+			Email: entry.Email,
+		})
+		if err != nil {
+			r.fail(login, err)
+			continue
+		}
+		invitations[login] = invitation
+		toUpdate[login] = entry
+	}
+
+	// Invitations normally refer to the user they created. Any that don't
+	// are resolved with a single further listing of all users.
+	unresolved := map[string]string{}
+	for login, invitation := range invitations {
+		if invitation.UserID != 0 {
+			r.ids[login] = invitation.UserID
+			r.invited[invitation.UserID] = true
+		} else {
+			unresolved[strings.ToLower(desired[login].Email)] = login
+		}
+	}
+	if len(unresolved) > 0 {
+		err := r.client.Users.Each(ctx, func(user *api.User) (bool, error) {
+			if login, ok := unresolved[strings.ToLower(user.Email)]; ok {
+				r.ids[login] = user.ID
+				r.invited[user.ID] = true
+				u := *user
+				byId[u.ID] = &u
+				delete(unresolved, strings.ToLower(user.Email))
+			}
+			return len(unresolved) > 0, nil
+		})
+		if err != nil {
+			return err
+		}
+		for email, login := range unresolved {
+			r.fail(login, fmt.Errorf("invited user %v is not in user list", email))
+			delete(toUpdate, login)
+		}
+	}
+
+	for login, entry := range toUpdate {
+		id := r.ids[login]
+		if user, ok := byId[id]; ok && invitations[login] == nil && rosterUserMatches(user, entry) {
+			continue
+		}
+
+		// The update starts from the user as listed, so that attributes
+		// the roster doesn't manage, such as admin access, are kept.
+		user := &api.User{}
+		if existing, ok := byId[id]; ok {
+			u := *existing
+			user = &u
+		}
+		user.Username = entry.Login
+		user.Email = entry.Email
+		user.Name = entry.Name
+		user.FirstName = ""
+		user.LastName = ""
+
+		err := r.client.Users.Update(ctx, id, user)
+		if err != nil {
+			r.fail(login, err)
+		}
+	}
+
+	return nil
+}
+
+func rosterUserMatches(user *api.User, entry *rosterEntry) bool {
+	return user.Username == entry.Login &&
+		strings.EqualFold(user.Email, entry.Email) &&
+		normalizeName(user.Name) == normalizeName(entry.Name)
+}
+
+func (r *rosterReconciler) removeUser(ctx context.Context, user *api.User) error {
+	if r.onDestroy == "abandon" || !r.invited[user.ID] {
+		return nil
+	}
+	if user.IsAccountOwner {
+		return fmt.Errorf("user is the account owner and so can't be deleted or deactivated")
+	}
+	if r.onDestroy == "deactivate" {
+		return DeactivateUser(ctx, r.client, user.ID)
+	}
+	err := r.client.Users.Delete(ctx, user.ID)
+	if api.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// reconcileTeams adds each user to the teams listed for them, and removes
+// them from any team that was listed for them before but is no longer.
+// Teams that were never listed for a user are left alone, so that the
+// roster can be combined with teams managed in other ways.
+func (r *rosterReconciler) reconcileTeams(ctx context.Context, previous, desired map[string]*rosterEntry) error {
+	add := map[int]map[int]string{}
+	remove := map[int]map[int]string{}
+	change := func(changes map[int]map[int]string, teamId, userId int, login string) {
+		if changes[teamId] == nil {
+			changes[teamId] = map[int]string{}
+		}
+		changes[teamId][userId] = login
+	}
+
+	for login, entry := range desired {
+		userId, ok := r.ids[login]
+		if !ok {
+			continue
+		}
+		listed := map[int]bool{}
+		for _, teamId := range entry.TeamIDs {
+			listed[teamId] = true
+			change(add, teamId, userId, login)
+		}
+		if old, ok := previous[login]; ok {
+			for _, teamId := range old.TeamIDs {
+				if !listed[teamId] {
+					change(remove, teamId, userId, login)
+				}
+			}
+		}
+	}
+
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	teams := []*api.TeamWrite{}
+	found := map[int]bool{}
+	err := r.client.Teams.Each(ctx, func(team *api.TeamRead) (bool, error) {
+		found[team.ID] = true
+		if add[team.ID] == nil && remove[team.ID] == nil {
+			return true, nil
+		}
+
+		write := team.ToWrite()
+		members := map[int]bool{}
+		userIds := []int{}
+		changed := false
+		for _, userId := range write.UserIDs {
+			if _, ok := remove[team.ID][userId]; ok {
+				changed = true
+				continue
+			}
+			members[userId] = true
+			userIds = append(userIds, userId)
+		}
+		for userId := range add[team.ID] {
+			if !members[userId] {
+				changed = true
+				userIds = append(userIds, userId)
+			}
+		}
+
+		if changed {
+			write.UserIDs = userIds
+			teams = append(teams, write)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for teamId, users := range add {
+		if !found[teamId] {
+			for _, login := range users {
+				r.fail(login, fmt.Errorf("team %d does not exist", teamId))
+			}
+		}
+	}
+
+	for _, team := range teams {
+		_, err := r.client.Teams.Update(ctx, team.ID, team)
+		if err == nil {
+			continue
+		}
+		for _, login := range add[team.ID] {
+			r.fail(login, fmt.Errorf("could not update team %d: %s", team.ID, err))
+		}
+		for _, login := range remove[team.ID] {
+			r.fail(login, fmt.Errorf("could not update team %d: %s", team.ID, err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package beanstalk
+
+import (
+	"time"
+
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+// formatTimestamp converts a timestamp from the Beanstalk API into RFC3339
+// form so that it can be easily consumed elsewhere in a configuration.
+// Values that cannot be parsed are returned verbatim.
+func formatTimestamp(raw string) string {
+	if raw == "" {
+		return ""
+	}
+
+	t, err := api.ParseTime(raw)
+	if err != nil {
+		return raw
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,304 @@
+package beanstalk
+
+import (
+	"sort"
+	"strings"
+)
+
+// Beanstalk is a Rails application and so it identifies timezones using
+// the friendly names from Rails' ActiveSupport::TimeZone rather than the
+// IANA names. This table maps each Rails name to its IANA equivalent.
+var railsTimezones = map[string]string{
+	"International Date Line West": "Etc/GMT+12",
+	"Midway Island":                "Pacific/Midway",
+	"American Samoa":               "Pacific/Pago_Pago",
+	"Hawaii":                       "Pacific/Honolulu",
+	"Alaska":                       "America/Juneau",
+	"Pacific Time (US & Canada)":   "America/Los_Angeles",
+	"Tijuana":                      "America/Tijuana",
+	"Mountain Time (US & Canada)":  "America/Denver",
+	"Arizona":                      "America/Phoenix",
+	"Chihuahua":                    "America/Chihuahua",
+	"Mazatlan":                     "America/Mazatlan",
+	"Central Time (US & Canada)":   "America/Chicago",
+	"Saskatchewan":                 "America/Regina",
+	"Guadalajara":                  "America/Mexico_City",
+	"Mexico City":                  "America/Mexico_City",
+	"Monterrey":                    "America/Monterrey",
+	"Central America":              "America/Guatemala",
+	"Eastern Time (US & Canada)":   "America/New_York",
+	"Indiana (East)":               "America/Indiana/Indianapolis",
+	"Bogota":                       "America/Bogota",
+	"Lima":                         "America/Lima",
+	"Quito":                        "America/Lima",
+	"Atlantic Time (Canada)":       "America/Halifax",
+	"Caracas":                      "America/Caracas",
+	"La Paz":                       "America/La_Paz",
+	"Santiago":                     "America/Santiago",
+	"Newfoundland":                 "America/St_Johns",
+	"Brasilia":                     "America/Sao_Paulo",
+	"Buenos Aires":                 "America/Argentina/Buenos_Aires",
+	"Montevideo":                   "America/Montevideo",
+	"Georgetown":                   "America/Guyana",
+	"Greenland":                    "America/Godthab",
+	"Mid-Atlantic":                 "Atlantic/South_Georgia",
+	"Azores":                       "Atlantic/Azores",
+	"Cape Verde Is.":               "Atlantic/Cape_Verde",
+	"Dublin":                       "Europe/Dublin",
+	"Edinburgh":                    "Europe/London",
+	"Lisbon":                       "Europe/Lisbon",
+	"London":                       "Europe/London",
+	"Casablanca":                   "Africa/Casablanca",
+	"Monrovia":                     "Africa/Monrovia",
+	"UTC":                          "Etc/UTC",
+	"Belgrade":                     "Europe/Belgrade",
+	"Bratislava":                   "Europe/Bratislava",
+	"Budapest":                     "Europe/Budapest",
+	"Ljubljana":                    "Europe/Ljubljana",
+	"Prague":                       "Europe/Prague",
+	"Sarajevo":                     "Europe/Sarajevo",
+	"Skopje":                       "Europe/Skopje",
+	"Warsaw":                       "Europe/Warsaw",
+	"Zagreb":                       "Europe/Zagreb",
+	"Brussels":                     "Europe/Brussels",
+	"Copenhagen":                   "Europe/Copenhagen",
+	"Madrid":                       "Europe/Madrid",
+	"Paris":                        "Europe/Paris",
+	"Amsterdam":                    "Europe/Amsterdam",
+	"Berlin":                       "Europe/Berlin",
+	"Bern":                         "Europe/Berlin",
+	"Rome":                         "Europe/Rome",
+	"Stockholm":                    "Europe/Stockholm",
+	"Vienna":                       "Europe/Vienna",
+	"West Central Africa":          "Africa/Algiers",
+	"Bucharest":                    "Europe/Bucharest",
+	"Cairo":                        "Africa/Cairo",
+	"Helsinki":                     "Europe/Helsinki",
+	"Kyiv":                         "Europe/Kiev",
+	"Riga":                         "Europe/Riga",
+	"Sofia":                        "Europe/Sofia",
+	"Tallinn":                      "Europe/Tallinn",
+	"Vilnius":                      "Europe/Vilnius",
+	"Athens":                       "Europe/Athens",
+	"Istanbul":                     "Europe/Istanbul",
+	"Minsk":                        "Europe/Minsk",
+	"Jerusalem":                    "Asia/Jerusalem",
+	"Harare":                       "Africa/Harare",
+	"Pretoria":                     "Africa/Johannesburg",
+	"Kaliningrad":                  "Europe/Kaliningrad",
+	"Moscow":                       "Europe/Moscow",
+	"St. Petersburg":               "Europe/Moscow",
+	"Volgograd":                    "Europe/Volgograd",
+	"Samara":                       "Europe/Samara",
+	"Kuwait":                       "Asia/Kuwait",
+	"Riyadh":                       "Asia/Riyadh",
+	"Nairobi":                      "Africa/Nairobi",
+	"Baghdad":                      "Asia/Baghdad",
+	"Tehran":                       "Asia/Tehran",
+	"Abu Dhabi":                    "Asia/Muscat",
+	"Muscat":                       "Asia/Muscat",
+	"Baku":                         "Asia/Baku",
+	"Tbilisi":                      "Asia/Tbilisi",
+	"Yerevan":                      "Asia/Yerevan",
+	"Kabul":                        "Asia/Kabul",
+	"Ekaterinburg":                 "Asia/Yekaterinburg",
+	"Islamabad":                    "Asia/Karachi",
+	"Karachi":                      "Asia/Karachi",
+	"Tashkent":                     "Asia/Tashkent",
+	"Chennai":                      "Asia/Kolkata",
+	"Kolkata":                      "Asia/Kolkata",
+	"Mumbai":                       "Asia/Kolkata",
+	"New Delhi":                    "Asia/Kolkata",
+	"Kathmandu":                    "Asia/Kathmandu",
+	"Astana":                       "Asia/Dhaka",
+	"Dhaka":                        "Asia/Dhaka",
+	"Sri Jayawardenepura":          "Asia/Colombo",
+	"Almaty":                       "Asia/Almaty",
+	"Novosibirsk":                  "Asia/Novosibirsk",
+	"Rangoon":                      "Asia/Rangoon",
+	"Bangkok":                      "Asia/Bangkok",
+	"Hanoi":                        "Asia/Bangkok",
+	"Jakarta":                      "Asia/Jakarta",
+	"Krasnoyarsk":                  "Asia/Krasnoyarsk",
+	"Beijing":                      "Asia/Shanghai",
+	"Chongqing":                    "Asia/Chongqing",
+	"Hong Kong":                    "Asia/Hong_Kong",
+	"Urumqi":                       "Asia/Urumqi",
+	"Kuala Lumpur":                 "Asia/Kuala_Lumpur",
+	"Singapore":                    "Asia/Singapore",
+	"Taipei":                       "Asia/Taipei",
+	"Perth":                        "Australia/Perth",
+	"Irkutsk":                      "Asia/Irkutsk",
+	"Ulaanbaatar":                  "Asia/Ulaanbaatar",
+	"Seoul":                        "Asia/Seoul",
+	"Osaka":                        "Asia/Tokyo",
+	"Sapporo":                      "Asia/Tokyo",
+	"Tokyo":                        "Asia/Tokyo",
+	"Yakutsk":                      "Asia/Yakutsk",
+	"Darwin":                       "Australia/Darwin",
+	"Adelaide":                     "Australia/Adelaide",
+	"Canberra":                     "Australia/Melbourne",
+	"Melbourne":                    "Australia/Melbourne",
+	"Sydney":                       "Australia/Sydney",
+	"Brisbane":                     "Australia/Brisbane",
+	"Hobart":                       "Australia/Hobart",
+	"Vladivostok":                  "Asia/Vladivostok",
+	"Guam":                         "Pacific/Guam",
+	"Port Moresby":                 "Pacific/Port_Moresby",
+	"Magadan":                      "Asia/Magadan",
+	"Srednekolymsk":                "Asia/Srednekolymsk",
+	"Solomon Is.":                  "Pacific/Guadalcanal",
+	"New Caledonia":                "Pacific/Noumea",
+	"Fiji":                         "Pacific/Fiji",
+	"Kamchatka":                    "Asia/Kamchatka",
+	"Marshall Is.":                 "Pacific/Majuro",
+	"Auckland":                     "Pacific/Auckland",
+	"Wellington":                   "Pacific/Auckland",
+	"Nuku'alofa":                   "Pacific/Tongatapu",
+	"Tokelau Is.":                  "Pacific/Fakaofo",
+	"Chatham Is.":                  "Pacific/Chatham",
+	"Samoa":                        "Pacific/Apia",
+}
+
+// ianaTimezoneLinks maps other IANA names to the one that railsTimezones
+// uses for the same zone. Most are the renames and links from the tz
+// database, such as "Europe/Kyiv" for "Europe/Kiev", which describe exactly
+// the same zone as the one they map to. The few that don't are grouped
+// separately at the end.
+var ianaTimezoneLinks = map[string]string{
+	// Renamed zones, whose current names are newer than Rails' table.
+	"America/Nuuk":         "America/Godthab",
+	"Asia/Yangon":          "Asia/Rangoon",
+	"Europe/Kyiv":          "Europe/Kiev",
+	"Asia/Calcutta":        "Asia/Kolkata",
+	"Asia/Katmandu":        "Asia/Kathmandu",
+	"Asia/Dacca":           "Asia/Dhaka",
+	"Asia/Ulan_Bator":      "Asia/Ulaanbaatar",
+	"Asia/Kashgar":         "Asia/Urumqi",
+	"America/Buenos_Aires": "America/Argentina/Buenos_Aires",
+	"America/Indianapolis": "America/Indiana/Indianapolis",
+	"America/Fort_Wayne":   "America/Indiana/Indianapolis",
+
+	// Zones that the tz database now treats as links to another zone,
+	// or whose Rails entry is itself a link.
+	"Africa/Maputo":      "Africa/Harare",
+	"Asia/Dubai":         "Asia/Muscat",
+	"Asia/Aden":          "Asia/Riyadh",
+	"Asia/Phnom_Penh":    "Asia/Bangkok",
+	"Asia/Vientiane":     "Asia/Bangkok",
+	"Asia/Chungking":     "Asia/Chongqing",
+	"Asia/Tel_Aviv":      "Asia/Jerusalem",
+	"Asia/Istanbul":      "Europe/Istanbul",
+	"Europe/Oslo":        "Europe/Berlin",
+	"Europe/Luxembourg":  "Europe/Brussels",
+	"Europe/Monaco":      "Europe/Paris",
+	"Europe/Podgorica":   "Europe/Belgrade",
+	"Europe/Belfast":     "Europe/London",
+	"Pacific/Tarawa":     "Pacific/Majuro",
+	"Pacific/Samoa":      "Pacific/Pago_Pago",
+	"Australia/Canberra": "Australia/Sydney",
+
+	// Legacy country and region names.
+	"UTC":                  "Etc/UTC",
+	"Etc/Universal":        "Etc/UTC",
+	"Etc/Zulu":             "Etc/UTC",
+	"Universal":            "Etc/UTC",
+	"Zulu":                 "Etc/UTC",
+	"GB":                   "Europe/London",
+	"Eire":                 "Europe/Dublin",
+	"Portugal":             "Europe/Lisbon",
+	"Poland":               "Europe/Warsaw",
+	"Turkey":               "Europe/Istanbul",
+	"W-SU":                 "Europe/Moscow",
+	"Egypt":                "Africa/Cairo",
+	"Iran":                 "Asia/Tehran",
+	"Israel":               "Asia/Jerusalem",
+	"Japan":                "Asia/Tokyo",
+	"ROK":                  "Asia/Seoul",
+	"ROC":                  "Asia/Taipei",
+	"PRC":                  "Asia/Shanghai",
+	"Hongkong":             "Asia/Hong_Kong",
+	"Singapore":            "Asia/Singapore",
+	"NZ":                   "Pacific/Auckland",
+	"NZ-CHAT":              "Pacific/Chatham",
+	"US/Pacific":           "America/Los_Angeles",
+	"US/Mountain":          "America/Denver",
+	"US/Arizona":           "America/Phoenix",
+	"US/Central":           "America/Chicago",
+	"US/Eastern":           "America/New_York",
+	"US/East-Indiana":      "America/Indiana/Indianapolis",
+	"US/Hawaii":            "Pacific/Honolulu",
+	"US/Samoa":             "Pacific/Pago_Pago",
+	"Canada/Atlantic":      "America/Halifax",
+	"Canada/Newfoundland":  "America/St_Johns",
+	"Canada/Saskatchewan":  "America/Regina",
+	"Mexico/General":       "America/Mexico_City",
+	"Mexico/BajaNorte":     "America/Tijuana",
+	"Brazil/East":          "America/Sao_Paulo",
+	"Chile/Continental":    "America/Santiago",
+	"Australia/ACT":        "Australia/Sydney",
+	"Australia/NSW":        "Australia/Sydney",
+	"Australia/Victoria":   "Australia/Melbourne",
+	"Australia/Queensland": "Australia/Brisbane",
+	"Australia/Tasmania":   "Australia/Hobart",
+	"Australia/West":       "Australia/Perth",
+	"Australia/South":      "Australia/Adelaide",
+	"Australia/North":      "Australia/Darwin",
+
+	// Distinct zones with a different history, but the same current
+	// offset and rules as the zone that Rails uses for their region,
+	// as Rails' own "Alaska" and "Hanoi" entries already assume.
+	"America/Anchorage": "America/Juneau",
+	"US/Alaska":         "America/Juneau",
+	"Asia/Ho_Chi_Minh":  "Asia/Bangkok",
+	"Asia/Saigon":       "Asia/Bangkok",
+}
+
+// ianaTimezones maps each IANA name in railsTimezones back to a Rails
+// name. Where several Rails names share a zone, the one named after the
+// zone's city is preferred, so that "Europe/London" is "London" rather
+// than "Edinburgh", and otherwise the first in alphabetical order is
+// used, so that the choice doesn't vary between runs.
+var ianaTimezones = map[string]string{}
+
+func init() {
+	railsNames := make([]string, 0, len(railsTimezones))
+	for name := range railsTimezones {
+		railsNames = append(railsNames, name)
+	}
+	sort.Strings(railsNames)
+
+	for _, name := range railsNames {
+		iana := railsTimezones[name]
+		city := strings.Replace(iana[strings.LastIndex(iana, "/")+1:], "_", " ", -1)
+		if _, exists := ianaTimezones[iana]; !exists || name == city {
+			ianaTimezones[iana] = name
+		}
+	}
+}
+
+// railsTimezoneName returns the name that Beanstalk uses for the given
+// timezone, which may be given either by that name or by any of its IANA
+// names. Names that are neither are returned unchanged.
+func railsTimezoneName(name string) string {
+	if _, ok := railsTimezones[name]; ok {
+		return name
+	}
+	if railsName, ok := ianaTimezones[ianaTimezoneName(name)]; ok {
+		return railsName
+	}
+	return name
+}
+
+// ianaTimezoneName returns the IANA name that railsTimezones uses for the
+// given timezone, which may be given either by its Rails name or by any
+// of its IANA names.
+func ianaTimezoneName(name string) string {
+	if iana, ok := railsTimezones[name]; ok {
+		return iana
+	}
+	if iana, ok := ianaTimezoneLinks[name]; ok {
+		return iana
+	}
+	return name
+}
@@ -0,0 +1,154 @@
+package beanstalk
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// These validation functions mirror the rules that Beanstalk itself
+// applies, so that mistakes are reported at plan time rather than as
+// an opaque HTTP error during apply.
+
+const maxRepositoryNameLength = 100
+
+var repositoryNameRegexp = regexp.MustCompile("^[A-Za-z0-9][A-Za-z0-9_-]*$")
+
+var colorLabels = []string{
+	"white",
+	"pink",
+	"red",
+	"red-orange",
+	"orange",
+	"yellow",
+	"yellow-green",
+	"aqua-green",
+	"green",
+	"green-blue",
+	"sky-blue",
+	"light-blue",
+	"blue",
+	"orchid",
+	"violet",
+	"brown",
+	"black",
+	"grey",
+}
+
+var vcsTypes = []string{
+	"git",
+	"subversion",
+}
+
+var userOnDestroyActions = []string{
+	"delete",
+	"deactivate",
+	"abandon",
+}
+
+func validateRepositoryName(v interface{}, k string) (ws []string, es []error) {
+	name := v.(string)
+
+	if len(name) == 0 || len(name) > maxRepositoryNameLength {
+		es = append(es, fmt.Errorf(
+			"%s must be between 1 and %d characters long", k, maxRepositoryNameLength,
+		))
+	}
+
+	if !repositoryNameRegexp.MatchString(name) {
+		es = append(es, fmt.Errorf(
+			"%s may contain only letters, digits, dashes and underscores, and must start with a letter or digit", k,
+		))
+	}
+
+	return
+}
+
+func validateColorLabel(v interface{}, k string) (ws []string, es []error) {
+	// The API documentation gives the colors with a "label-" prefix,
+	// but Beanstalk accepts them with or without it.
+	label := strings.TrimPrefix(v.(string), "label-")
+
+	for _, valid := range colorLabels {
+		if label == valid {
+			return
+		}
+	}
+
+	es = append(es, fmt.Errorf(
+		"%s must be one of: %s", k, strings.Join(colorLabels, ", "),
+	))
+	return
+}
+
+func validateVCS(v interface{}, k string) (ws []string, es []error) {
+	vcs := v.(string)
+
+	for _, valid := range vcsTypes {
+		if vcs == valid {
+			return
+		}
+	}
+
+	es = append(es, fmt.Errorf(
+		"%s must be one of: %s", k, strings.Join(vcsTypes, ", "),
+	))
+	return
+}
+
+func validateUserOnDestroy(v interface{}, k string) (ws []string, es []error) {
+	action := v.(string)
+
+	for _, valid := range userOnDestroyActions {
+		if action == valid {
+			return
+		}
+	}
+
+	es = append(es, fmt.Errorf(
+		"%s must be one of: %s", k, strings.Join(userOnDestroyActions, ", "),
+	))
+	return
+}
+
+func validateTimezone(v interface{}, k string) (ws []string, es []error) {
+	timezone := v.(string)
+
+	if _, ok := railsTimezones[timezone]; ok {
+		return
+	}
+	if _, ok := ianaTimezones[ianaTimezoneName(timezone)]; !ok {
+		es = append(es, fmt.Errorf(
+			"%s must be one of the timezone names offered in the Beanstalk profile settings, such as \"London\" or \"Pacific Time (US & Canada)\", or an IANA name for one of those zones, such as \"Europe/London\"", k,
+		))
+	}
+
+	return
+}
+
+func validateEmail(v interface{}, k string) (ws []string, es []error) {
+	email := v.(string)
+
+	// ParseAddress also accepts forms such as "Name <address>", which
+	// Beanstalk doesn't, so we insist on getting back what we gave.
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		es = append(es, fmt.Errorf(
+			"%s must be a plain email address, such as \"alice@example.com\"", k,
+		))
+	}
+
+	return
+}
+
+func validateRFC3339(v interface{}, k string) (ws []string, es []error) {
+	if _, err := time.Parse(time.RFC3339, v.(string)); err != nil {
+		es = append(es, fmt.Errorf(
+			"%s must be a timestamp in RFC3339 format, such as \"2015-06-01T00:00:00Z\"", k,
+		))
+	}
+
+	return
+}
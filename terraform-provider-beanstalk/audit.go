@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+const auditUsage = `Usage: terraform-provider-beanstalk audit -account NAME [-format json|csv] [-diff FILE] [-snapshot FILE] [-out FILE]
+
+Reports which users can access which repositories in a Beanstalk account,
+combining the permissions granted via teams, those granted directly to
+users, and the implicit access of account owners and admins. This is read
+from Beanstalk itself, regardless of any Terraform configuration or state.
+
+Given -diff, reports only the differences from a previous report, which
+must have been written in JSON format. Given -snapshot, the full report is
+also written in JSON format to the given file, which may be the same as the
+previous report so that it becomes the baseline for the next comparison.
+
+Credentials are read from the BEANSTALK_USERNAME and BEANSTALK_ACCESS_TOKEN
+environment variables, as for the provider itself.
+
+`
+
+// accessEntry describes the access that one user has to one repository.
+// Every user that has any access to a repository can read it.
+type accessEntry struct {
+	UserID               int      `json:"user_id"`
+	Username             string   `json:"username"`
+	RepositoryID         int      `json:"repository_id"`
+	RepositoryName       string   `json:"repository_name"`
+	Write                bool     `json:"write"`
+	Deploy               bool     `json:"deploy"`
+	DeployEnvironmentIDs []int    `json:"deploy_environment_ids,omitempty"`
+	ConfigureDeployments bool     `json:"configure_deployments"`
+	Sources              []string `json:"sources"`
+}
+
+type accessSnapshot struct {
+	Account     string         `json:"account"`
+	GeneratedAt string         `json:"generated_at"`
+	Entries     []*accessEntry `json:"entries"`
+}
+
+type accessChange struct {
+	Change string       `json:"change"`
+	Before *accessEntry `json:"before,omitempty"`
+	After  *accessEntry `json:"after,omitempty"`
+}
+
+type accessKey struct {
+	UserID       int
+	RepositoryID int
+}
+
+func runAudit(args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, auditUsage)
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "name of the Beanstalk account to audit")
+	format := flags.String("format", "json", "output format, either json or csv")
+	diffPath := flags.String("diff", "", "previous JSON report to compare against")
+	snapshotPath := flags.String("snapshot", "", "file to also write the full JSON report to")
+	outPath := flags.String("out", "", "file to write the report to, instead of standard output")
+	flags.Parse(args)
+
+	if *accountName == "" || (*format != "json" && *format != "csv") {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var previous *accessSnapshot
+	if *diffPath != "" {
+		raw, err := ioutil.ReadFile(*diffPath)
+		if err != nil {
+			return err
+		}
+		previous = &accessSnapshot{}
+		if err := json.Unmarshal(raw, previous); err != nil {
+			return fmt.Errorf("can't read previous report %s: %s", *diffPath, err)
+		}
+	}
+
+	client, err := newClient(*accountName)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := auditAccess(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	snapshot.Account = *accountName
+
+	if *snapshotPath != "" {
+		err := writeJSONFile(*snapshotPath, snapshot)
+		if err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if previous != nil {
+		changes := diffAccess(previous, snapshot)
+		if *format == "csv" {
+			return writeAccessChangesCSV(out, changes)
+		}
+		return writeJSON(out, changes)
+	}
+
+	if *format == "csv" {
+		return writeAccessCSV(out, snapshot.Entries)
+	}
+	return writeJSON(out, snapshot)
+}
+
+// auditAccess builds the permission matrix of the account that the
+// client is configured for.
+func auditAccess(ctx context.Context, client *api.Client) (*accessSnapshot, error) {
+	repositories := []*api.Repository{}
+	repositoryNames := map[int]string{}
+	err := client.Repositories.Each(ctx, func(repo *api.Repository) (bool, error) {
+		repositories = append(repositories, repo)
+		repositoryNames[repo.ID] = repo.Name
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := []*api.User{}
+	usernames := map[int]string{}
+	err = client.Users.Each(ctx, func(user *api.User) (bool, error) {
+		users = append(users, user)
+		usernames[user.ID] = user.Username
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[accessKey]*accessEntry{}
+	entry := func(userId, repositoryId int, source string) *accessEntry {
+		key := accessKey{userId, repositoryId}
+		e, ok := entries[key]
+		if !ok {
+			e = &accessEntry{
+				UserID:         userId,
+				Username:       usernames[userId],
+				RepositoryID:   repositoryId,
+				RepositoryName: repositoryNames[repositoryId],
+			}
+			entries[key] = e
+		}
+		for _, existing := range e.Sources {
+			if existing == source {
+				return e
+			}
+		}
+		e.Sources = append(e.Sources, source)
+		return e
+	}
+
+	for _, user := range users {
+		if user.IsAccountOwner || user.IsAccountAdmin {
+			source := "account admin"
+			if user.IsAccountOwner {
+				source = "account owner"
+			}
+			for _, repo := range repositories {
+				e := entry(user.ID, repo.ID, source)
+				e.Write = true
+				e.Deploy = true
+				e.ConfigureDeployments = true
+			}
+			continue
+		}
+
+		permissions, err := client.Permissions.List(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range permissions {
+			e := entry(user.ID, permission.RepositoryID, "direct")
+			e.Write = e.Write || permission.CanWrite
+			e.Deploy = e.Deploy || permission.FullDeploymentsAccess
+			if permission.ServerEnvironmentID != 0 {
+				e.DeployEnvironmentIDs = append(e.DeployEnvironmentIDs, permission.ServerEnvironmentID)
+			}
+		}
+	}
+
+	err = client.Teams.Each(ctx, func(team *api.TeamRead) (bool, error) {
+		source := "team " + team.Name
+		for _, user := range team.Users {
+			for _, permission := range team.Permissions {
+				e := entry(user.ID, permission.RepositoryID, source)
+				e.Write = e.Write || permission.CanWrite
+				e.Deploy = e.Deploy || permission.CanDeploy
+				e.ConfigureDeployments = e.ConfigureDeployments || permission.CanConfigureDeployments
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &accessSnapshot{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Entries:     make([]*accessEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		sort.Ints(e.DeployEnvironmentIDs)
+		sort.Strings(e.Sources)
+		snapshot.Entries = append(snapshot.Entries, e)
+	}
+	sort.Slice(snapshot.Entries, func(i, j int) bool {
+		return accessEntryLess(snapshot.Entries[i], snapshot.Entries[j])
+	})
+
+	return snapshot, nil
+}
+
+func accessEntryLess(a, b *accessEntry) bool {
+	if a.Username != b.Username {
+		return a.Username < b.Username
+	}
+	if a.RepositoryName != b.RepositoryName {
+		return a.RepositoryName < b.RepositoryName
+	}
+	if a.UserID != b.UserID {
+		return a.UserID < b.UserID
+	}
+	return a.RepositoryID < b.RepositoryID
+}
+
+// diffAccess compares two permission matrices. Users and repositories are
+// matched by id, so renaming either isn't reported as a change. Only
+// changes to what a user can do are reported, not changes to how they
+// came to be able to do it.
+func diffAccess(before, after *accessSnapshot) []*accessChange {
+	beforeEntries := map[accessKey]*accessEntry{}
+	for _, e := range before.Entries {
+		beforeEntries[accessKey{e.UserID, e.RepositoryID}] = e
+	}
+	afterEntries := map[accessKey]*accessEntry{}
+	for _, e := range after.Entries {
+		afterEntries[accessKey{e.UserID, e.RepositoryID}] = e
+	}
+
+	changes := []*accessChange{}
+	for key, a := range afterEntries {
+		b, ok := beforeEntries[key]
+		switch {
+		case !ok:
+			changes = append(changes, &accessChange{Change: "added", After: a})
+		case !sameAccess(a, b):
+			changes = append(changes, &accessChange{Change: "changed", Before: b, After: a})
+		}
+	}
+	for key, b := range beforeEntries {
+		if _, ok := afterEntries[key]; !ok {
+			changes = append(changes, &accessChange{Change: "removed", Before: b})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return accessEntryLess(changes[i].entry(), changes[j].entry())
+	})
+	return changes
+}
+
+// entry returns the most recent state of the access that changed.
+func (c *accessChange) entry() *accessEntry {
+	if c.After != nil {
+		return c.After
+	}
+	return c.Before
+}
+
+func sameAccess(a, b *accessEntry) bool {
+	return a.Write == b.Write &&
+		a.Deploy == b.Deploy &&
+		a.ConfigureDeployments == b.ConfigureDeployments &&
+		(len(a.DeployEnvironmentIDs) == 0 && len(b.DeployEnvironmentIDs) == 0 ||
+			reflect.DeepEqual(a.DeployEnvironmentIDs, b.DeployEnvironmentIDs))
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(raw, '\n'))
+	return err
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(f, v)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+var accessCSVHeader = []string{
+	"username", "repository", "write", "deploy", "deploy_environment_ids", "configure_deployments", "sources",
+}
+
+func accessCSVRecord(e *accessEntry) []string {
+	environmentIds := make([]string, len(e.DeployEnvironmentIDs))
+	for i, id := range e.DeployEnvironmentIDs {
+		environmentIds[i] = strconv.Itoa(id)
+	}
+	return []string{
+		e.Username,
+		e.RepositoryName,
+		strconv.FormatBool(e.Write),
+		strconv.FormatBool(e.Deploy),
+		strings.Join(environmentIds, " "),
+		strconv.FormatBool(e.ConfigureDeployments),
+		strings.Join(e.Sources, "; "),
+	}
+}
+
+func writeAccessCSV(w io.Writer, entries []*accessEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write(accessCSVHeader)
+	for _, e := range entries {
+		cw.Write(accessCSVRecord(e))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeAccessChangesCSV writes one row for each added or removed entry,
+// and two rows for each changed entry giving its old and new values.
+func writeAccessChangesCSV(w io.Writer, changes []*accessChange) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"change"}, accessCSVHeader...))
+	for _, c := range changes {
+		switch c.Change {
+		case "added":
+			cw.Write(append([]string{"added"}, accessCSVRecord(c.After)...))
+		case "removed":
+			cw.Write(append([]string{"removed"}, accessCSVRecord(c.Before)...))
+		default:
+			cw.Write(append([]string{"changed from"}, accessCSVRecord(c.Before)...))
+			cw.Write(append([]string{"changed to"}, accessCSVRecord(c.After)...))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
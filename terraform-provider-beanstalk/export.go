@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saymedia/terraform-beanstalk/beanstalk"
+)
+
+const exportUsage = `Usage: terraform-provider-beanstalk export -account NAME [-out DIR]
+
+Writes Terraform configuration describing the repositories, users, teams,
+code review settings and integrations of an existing Beanstalk account,
+along with a script of the "terraform import" commands needed to bring
+them under management.
+
+Credentials are read from the BEANSTALK_USERNAME and BEANSTALK_ACCESS_TOKEN
+environment variables, as for the provider itself.
+
+`
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, exportUsage)
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "name of the Beanstalk account to export")
+	outDir := flags.String("out", ".", "directory in which to write the generated files")
+	flags.Parse(args)
+
+	if *accountName == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, err := newClient(*accountName)
+	if err != nil {
+		return err
+	}
+
+	export, err := beanstalk.ExportAccount(context.Background(), client)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{}
+	for name, content := range export.Files {
+		files[name] = content
+	}
+	files["import.sh"] = []byte("#!/bin/sh\nset -e\n\n" + strings.Join(export.ImportCommands, "\n") + "\n")
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Existing files are never overwritten, since they may well be
+	// hand-written configuration.
+	for _, name := range names {
+		path := filepath.Join(*outDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		mode := os.FileMode(0644)
+		if name == "import.sh" {
+			mode = 0755
+		}
+		path := filepath.Join(*outDir, name)
+		if err := ioutil.WriteFile(path, files[name], mode); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+	}
+
+	return nil
+}
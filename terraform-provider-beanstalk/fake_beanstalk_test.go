@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+// fakeBeanstalk is an in-memory stand-in for the parts of the Beanstalk
+// API that deal with users, invitations, teams and permissions. It keeps
+// just enough state for the commands to be exercised end to end.
+type fakeBeanstalk struct {
+	mu          sync.Mutex
+	lastId      int
+	users       map[int]*api.User
+	teams       map[int]*api.TeamWrite
+	permissions map[int]*api.Permission
+
+	// ignorePaging makes collections come back whole on every page, as
+	// some Beanstalk endpoints do.
+	ignorePaging bool
+}
+
+// newFakeBeanstalk starts a fake Beanstalk API and returns a client that
+// is configured to use it. The server is stopped when the test finishes.
+func newFakeBeanstalk(t *testing.T) (*fakeBeanstalk, *api.Client) {
+	fake := &fakeBeanstalk{
+		users:       map[int]*api.User{},
+		teams:       map[int]*api.TeamWrite{},
+		permissions: map[int]*api.Permission{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := api.NewClient(&api.ClientConfig{
+		AccountName: "example",
+		Username:    "admin",
+		AccessToken: "token",
+		BaseURL:     server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, client
+}
+
+func (f *fakeBeanstalk) addUser(user api.User) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastId++
+	user.ID = f.lastId
+	f.users[user.ID] = &user
+	return user.ID
+}
+
+func (f *fakeBeanstalk) addTeam(team api.TeamWrite) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastId++
+	team.ID = f.lastId
+	f.teams[team.ID] = &team
+	return team.ID
+}
+
+func (f *fakeBeanstalk) addPermission(permission api.Permission) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastId++
+	permission.ID = f.lastId
+	f.permissions[permission.ID] = &permission
+	return permission.ID
+}
+
+func (f *fakeBeanstalk) setTeamPermissions(id int, permissions map[string]api.TeamRepositoryPermissionsWrite) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.teams[id].Permissions = permissions
+}
+
+func (f *fakeBeanstalk) user(id int) *api.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, ok := f.users[id]; ok {
+		u := *user
+		return &u
+	}
+	return nil
+}
+
+func (f *fakeBeanstalk) team(id int) *api.TeamWrite {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if team, ok := f.teams[id]; ok {
+		t := *team
+		return &t
+	}
+	return nil
+}
+
+func (f *fakeBeanstalk) permissionCount(userId int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, permission := range f.permissions {
+		if permission.UserID == userId {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *fakeBeanstalk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimSuffix(strings.Trim(r.URL.Path, "/"), ".json")
+	parts := strings.Split(path, "/")
+	var id int
+	if len(parts) == 2 {
+		var err error
+		if id, err = strconv.Atoi(parts[1]); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	switch {
+	case r.Method == "GET" && path == "users":
+		items := []interface{}{}
+		for _, id := range sortedIds(f.users) {
+			items = append(items, api.UserWrap{User: *f.users[id]})
+		}
+		f.writePage(w, r, items)
+
+	case parts[0] == "users" && len(parts) == 2:
+		user, ok := f.users[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			writeFakeJSON(w, api.UserWrap{User: *user})
+		case "PUT":
+			req := &api.UserWrap{}
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			updated := req.User
+			updated.ID = id
+			updated.IsAccountOwner = user.IsAccountOwner
+			if updated.Timezone == "" {
+				updated.Timezone = user.Timezone
+			}
+			f.users[id] = &updated
+			writeFakeJSON(w, api.UserWrap{User: updated})
+		case "DELETE":
+			delete(f.users, id)
+			for _, team := range f.teams {
+				team.UserIDs = withoutId(team.UserIDs, id)
+			}
+		}
+
+	case r.Method == "POST" && path == "invitations":
+		req := &api.InvitationCreateRequestWrap{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		invited := req.Invitation.User
+		f.lastId++
+		invited.ID = f.lastId
+		invited.Username = strings.Split(invited.Email, "@")[0]
+		invited.Timezone = "London"
+		f.users[invited.ID] = &invited
+		f.lastId++
+		writeFakeJSON(w, api.InvitationWrap{Invitation: api.Invitation{
+			ID:     f.lastId,
+			UserID: invited.ID,
+			Name:   invited.Name,
+			Email:  invited.Email,
+		}})
+
+	case r.Method == "GET" && path == "teams":
+		items := []interface{}{}
+		for _, id := range sortedIds(f.teams) {
+			items = append(items, api.TeamReadWrap{Team: f.teamRead(f.teams[id])})
+		}
+		f.writePage(w, r, items)
+
+	case r.Method == "POST" && path == "teams":
+		team := &api.TeamWrite{}
+		if err := json.NewDecoder(r.Body).Decode(team); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.lastId++
+		team.ID = f.lastId
+		f.teams[team.ID] = team
+		writeFakeJSON(w, api.TeamReadWrap{Team: f.teamRead(team)})
+
+	case parts[0] == "teams" && len(parts) == 2:
+		team, ok := f.teams[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			writeFakeJSON(w, api.TeamReadWrap{Team: f.teamRead(team)})
+		case "PUT":
+			updated := &api.TeamWrite{}
+			if err := json.NewDecoder(r.Body).Decode(updated); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			updated.ID = id
+			f.teams[id] = updated
+			writeFakeJSON(w, api.TeamReadWrap{Team: f.teamRead(updated)})
+		case "DELETE":
+			delete(f.teams, id)
+		}
+
+	case r.Method == "GET" && parts[0] == "permissions" && len(parts) == 2:
+		items := []api.PermissionWrap{}
+		for _, permissionId := range sortedIds(f.permissions) {
+			if permission := f.permissions[permissionId]; permission.UserID == id {
+				items = append(items, api.PermissionWrap{Permission: *permission})
+			}
+		}
+		writeFakeJSON(w, items)
+
+	case r.Method == "DELETE" && parts[0] == "permissions" && len(parts) == 2:
+		if _, ok := f.permissions[id]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.permissions, id)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeBeanstalk) teamRead(team *api.TeamWrite) api.TeamRead {
+	read := api.TeamRead{
+		ID:          team.ID,
+		Name:        team.Name,
+		ColorLabel:  team.ColorLabel,
+		Users:       []api.User{},
+		Permissions: []api.TeamRepositoryPermissionsRead{},
+	}
+	for _, userId := range team.UserIDs {
+		if user, ok := f.users[userId]; ok {
+			read.Users = append(read.Users, *user)
+		}
+	}
+	for repositoryId, permissions := range team.Permissions {
+		id, _ := strconv.Atoi(repositoryId)
+		read.Permissions = append(read.Permissions, api.TeamRepositoryPermissionsRead{
+			RepositoryID:            id,
+			CanWrite:                permissions.CanWrite,
+			CanDeploy:               permissions.CanDeploy,
+			CanConfigureDeployments: permissions.CanConfigureDeployments,
+		})
+	}
+	return read
+}
+
+func writeFakeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writePage writes one page of a collection, as selected by the page
+// and per_page arguments that the client sends.
+func (f *fakeBeanstalk) writePage(w http.ResponseWriter, r *http.Request, items []interface{}) {
+	if f.ignorePaging {
+		writeFakeJSON(w, items)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = len(items)
+	}
+
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	writeFakeJSON(w, items[start:end])
+}
+
+func sortedIds(m interface{}) []int {
+	ids := []int{}
+	switch m := m.(type) {
+	case map[int]*api.User:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[int]*api.TeamWrite:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[int]*api.Permission:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func withoutId(ids []int, id int) []int {
+	remaining := []int{}
+	for _, existing := range ids {
+		if existing != id {
+			remaining = append(remaining, existing)
+		}
+	}
+	return remaining
+}
@@ -1,12 +1,51 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/hashicorp/terraform/plugin"
 	"github.com/saymedia/terraform-beanstalk/beanstalk"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
 )
 
+// commands are the standalone commands that the plugin program offers
+// alongside the plugin itself.
+var commands = map[string]func(args []string) error{
+	"export": runExport,
+	"audit":  runAudit,
+	"scim":   runSCIM,
+}
+
 func main() {
+	// Terraform runs the plugin without arguments, so any argument
+	// selects one of the standalone commands instead.
+	if len(os.Args) > 1 {
+		command, ok := commands[os.Args[1]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", os.Args[1])
+			os.Exit(2)
+		}
+		if err := command(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: beanstalk.Provider,
 	})
 }
+
+// newClient returns a client for the given account, using the same
+// credential environment variables as the provider. BEANSTALK_API_URL
+// may be set to direct requests to a fake API for testing.
+func newClient(accountName string) (*api.Client, error) {
+	return api.NewClient(&api.ClientConfig{
+		AccountName: accountName,
+		Username:    os.Getenv("BEANSTALK_USERNAME"),
+		AccessToken: os.Getenv("BEANSTALK_ACCESS_TOKEN"),
+		BaseURL:     os.Getenv("BEANSTALK_API_URL"),
+	})
+}
@@ -0,0 +1,868 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saymedia/terraform-beanstalk/beanstalk"
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+const scimUsage = `Usage: terraform-provider-beanstalk scim -account NAME [-listen ADDR]
+
+Runs an HTTP server that accepts SCIM 2.0 requests for Users and Groups
+under /scim/v2/, so that an identity provider can provision users and
+teams in a Beanstalk account. SCIM users are Beanstalk users and SCIM
+groups are Beanstalk teams.
+
+Requests must carry the bearer token given in the SCIM_BEARER_TOKEN
+environment variable. Beanstalk credentials are read from the
+BEANSTALK_USERNAME and BEANSTALK_ACCESS_TOKEN environment variables, as
+for the provider itself, and BEANSTALK_API_URL may be set to use a fake
+API for testing.
+
+`
+
+const (
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+func runSCIM(args []string) error {
+	flags := flag.NewFlagSet("scim", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, scimUsage)
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "name of the Beanstalk account to provision")
+	listen := flags.String("listen", "localhost:8080", "address to listen on")
+	flags.Parse(args)
+
+	token := os.Getenv("SCIM_BEARER_TOKEN")
+	if *accountName == "" || token == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, err := newClient(*accountName)
+	if err != nil {
+		return err
+	}
+
+	server := &scimServer{
+		client: client,
+		token:  token,
+	}
+
+	log.Printf("Serving SCIM for Beanstalk account %s on %s", *accountName, *listen)
+	return http.ListenAndServe(*listen, server)
+}
+
+type scimServer struct {
+	client *api.Client
+	token  string
+}
+
+// scimError is an error with the HTTP status that should be reported
+// for it.
+type scimError struct {
+	Status int
+	Detail string
+}
+
+func (err *scimError) Error() string {
+	return err.Detail
+}
+
+type scimUser struct {
+	Schemas  []string      `json:"schemas"`
+	ID       string        `json:"id,omitempty"`
+	UserName string        `json:"userName"`
+	Name     *scimName     `json:"name,omitempty"`
+	Emails   []scimEmail   `json:"emails,omitempty"`
+	Active   *bool         `json:"active,omitempty"`
+	Groups   []scimMember  `json:"groups,omitempty"`
+	Meta     *scimMetadata `json:"meta,omitempty"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []scimMember  `json:"members"`
+	Meta        *scimMetadata `json:"meta,omitempty"`
+}
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimMetadata struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type scimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (s *scimServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+s.token)) != 1 {
+		writeSCIMError(w, &scimError{http.StatusUnauthorized, "invalid bearer token"})
+		return
+	}
+
+	var result interface{}
+	status := http.StatusOK
+	var err error
+
+	const prefix = "/scim/v2/"
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case !strings.HasPrefix(r.URL.Path, prefix):
+		err = &scimError{http.StatusNotFound, "no such endpoint"}
+	case parts[0] == "Users" && len(parts) == 1 && r.Method == "GET":
+		result, err = s.listUsers(r)
+	case parts[0] == "Users" && len(parts) == 1 && r.Method == "POST":
+		status = http.StatusCreated
+		result, err = s.createUser(r)
+	case parts[0] == "Users" && len(parts) == 2:
+		result, err = s.handleUser(r, parts[1])
+	case parts[0] == "Groups" && len(parts) == 1 && r.Method == "GET":
+		result, err = s.listGroups(r)
+	case parts[0] == "Groups" && len(parts) == 1 && r.Method == "POST":
+		status = http.StatusCreated
+		result, err = s.createGroup(r)
+	case parts[0] == "Groups" && len(parts) == 2:
+		result, err = s.handleGroup(r, parts[1])
+	default:
+		err = &scimError{http.StatusNotFound, "no such endpoint"}
+	}
+
+	if err != nil {
+		log.Printf("SCIM %s %s failed: %s", r.Method, r.URL.Path, err)
+		writeSCIMError(w, err)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+func writeSCIMError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch e := err.(type) {
+	case *scimError:
+		status = e.Status
+	case *api.NotFoundError:
+		status = http.StatusNotFound
+	}
+
+	body := map[string]interface{}{
+		"schemas": []string{scimErrorSchema},
+		"status":  strconv.Itoa(status),
+		"detail":  err.Error(),
+	}
+	if status == http.StatusConflict {
+		// The only conflicts reported are of existing users.
+		body["scimType"] = "uniqueness"
+	}
+
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func decodeSCIMBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &scimError{http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err)}
+	}
+	return nil
+}
+
+func parseSCIMId(raw string) (int, error) {
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &scimError{http.StatusNotFound, fmt.Sprintf("no such resource %q", raw)}
+	}
+	return id, nil
+}
+
+// Only the simple equality filters that identity providers use to look
+// for existing users and groups are supported.
+var scimFilterRegexp = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+func parseSCIMFilter(r *http.Request, allowed ...string) (string, string, error) {
+	filter := r.URL.Query().Get("filter")
+	if filter == "" {
+		return "", "", nil
+	}
+
+	match := scimFilterRegexp.FindStringSubmatch(filter)
+	if match != nil {
+		for _, attr := range allowed {
+			if strings.EqualFold(match[1], attr) {
+				value, err := strconv.Unquote(`"` + match[2] + `"`)
+				if err == nil {
+					return attr, value, nil
+				}
+			}
+		}
+	}
+	return "", "", &scimError{http.StatusBadRequest, fmt.Sprintf("unsupported filter %q", filter)}
+}
+
+// paginate applies the SCIM startIndex and count parameters to a list of
+// resources.
+func paginate(r *http.Request, resources []interface{}) *scimListResponse {
+	startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 || count > len(resources) {
+		// Limiting count also keeps the end of the page from
+		// overflowing.
+		count = len(resources)
+	}
+
+	page := []interface{}{}
+	if startIndex <= len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		page = resources[startIndex-1 : end]
+	}
+
+	return &scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+func userToSCIM(user *api.User, active bool) *scimUser {
+	return &scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       strconv.Itoa(user.ID),
+		UserName: user.Username,
+		Name: &scimName{
+			Formatted:  user.Name,
+			GivenName:  user.FirstName,
+			FamilyName: user.LastName,
+		},
+		Emails: []scimEmail{
+			{Value: user.Email, Primary: true},
+		},
+		Active: &active,
+		Meta: &scimMetadata{
+			ResourceType: "User",
+			Location:     "/scim/v2/Users/" + strconv.Itoa(user.ID),
+		},
+	}
+}
+
+// fullName returns the user's name in the form that Beanstalk expects.
+func (u *scimUser) fullName() string {
+	if u.Name == nil {
+		return ""
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+func (u *scimUser) primaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// activeUsers reports which of the given users are active. Beanstalk
+// can't disable a user, and so deactivating a user instead removes all of
+// their access. A user is therefore reported as active if they have any
+// access at all: as an owner or admin, via a team, or via a permission
+// granted to them directly.
+func (s *scimServer) activeUsers(ctx context.Context, users []*api.User) (map[int]bool, error) {
+	active := map[int]bool{}
+	for _, user := range users {
+		if user.IsAccountOwner || user.IsAccountAdmin {
+			active[user.ID] = true
+		}
+	}
+
+	err := s.client.Teams.Each(ctx, func(team *api.TeamRead) (bool, error) {
+		for _, user := range team.Users {
+			active[user.ID] = true
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if active[user.ID] {
+			continue
+		}
+		permissions, err := s.client.Permissions.List(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		active[user.ID] = len(permissions) > 0
+	}
+
+	return active, nil
+}
+
+func (s *scimServer) userResource(ctx context.Context, user *api.User) (interface{}, error) {
+	active, err := s.activeUsers(ctx, []*api.User{user})
+	if err != nil {
+		return nil, err
+	}
+	return userToSCIM(user, active[user.ID]), nil
+}
+
+func (s *scimServer) listUsers(r *http.Request) (interface{}, error) {
+	attr, value, err := parseSCIMFilter(r, "userName", "emails.value")
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []interface{}{}
+	err = s.client.Users.Each(r.Context(), func(user *api.User) (bool, error) {
+		switch attr {
+		case "userName":
+			if !strings.EqualFold(user.Username, value) {
+				return true, nil
+			}
+		case "emails.value":
+			if !strings.EqualFold(user.Email, value) {
+				return true, nil
+			}
+		}
+		resources = append(resources, user)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Working out whether users are active takes further requests, so
+	// it's done only for the requested page.
+	list := paginate(r, resources)
+	users := make([]*api.User, len(list.Resources))
+	for i, resource := range list.Resources {
+		users[i] = resource.(*api.User)
+	}
+	active, err := s.activeUsers(r.Context(), users)
+	if err != nil {
+		return nil, err
+	}
+	for i, user := range users {
+		list.Resources[i] = userToSCIM(user, active[user.ID])
+	}
+	return list, nil
+}
+
+func (s *scimServer) createUser(r *http.Request) (interface{}, error) {
+	req := &scimUser{}
+	if err := decodeSCIMBody(r, req); err != nil {
+		return nil, err
+	}
+
+	email := req.primaryEmail()
+	if req.UserName == "" || email == "" || req.fullName() == "" {
+		return nil, &scimError{http.StatusBadRequest, "userName, name and an email address are required"}
+	}
+
+	ctx := r.Context()
+
+	// Identity providers rely on a conflict to tell them to look up the
+	// existing user instead, so it's reported before Beanstalk can
+	// reject the invitation less helpfully.
+	var existing *api.User
+	err := s.client.Users.Each(ctx, func(user *api.User) (bool, error) {
+		if strings.EqualFold(user.Username, req.UserName) || strings.EqualFold(user.Email, email) {
+			u := *user
+			existing = &u
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, &scimError{
+			http.StatusConflict,
+			fmt.Sprintf("user %d already has that userName or email address", existing.ID),
+		}
+	}
+
+	// As with the beanstalk_user resource, the user is created by
+	// inviting them and then updated to set their login.
+	invitation, err := s.client.Invitations.Create(ctx, &api.User{
+		Name:  req.fullName(),
+		Email: email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	id := invitation.UserID
+	if id == 0 {
+		user, err := s.client.Users.FindByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		id = user.ID
+	}
+
+	return s.updateUser(ctx, id, req)
+}
+
+func (s *scimServer) handleUser(r *http.Request, rawId string) (interface{}, error) {
+	id, err := parseSCIMId(rawId)
+	if err != nil {
+		return nil, err
+	}
+	ctx := r.Context()
+
+	switch r.Method {
+	case "GET":
+		user, err := s.client.Users.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return s.userResource(ctx, user)
+
+	case "PUT":
+		req := &scimUser{}
+		if err := decodeSCIMBody(r, req); err != nil {
+			return nil, err
+		}
+		return s.updateUser(ctx, id, req)
+
+	case "PATCH":
+		user, err := s.client.Users.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		req := userToSCIM(user, true)
+		req.Active = nil
+		if err := applyUserPatch(r, req); err != nil {
+			return nil, err
+		}
+		return s.updateUser(ctx, id, req)
+
+	case "DELETE":
+		// Identity providers that want to keep a user's history mark
+		// the user as inactive instead, so this deletes outright.
+		user, err := s.client.Users.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if user.IsAccountOwner {
+			return nil, &scimError{
+				http.StatusBadRequest,
+				"the account owner can't be deleted; transfer ownership of the account first",
+			}
+		}
+		return nil, s.client.Users.Delete(ctx, id)
+	}
+
+	return nil, &scimError{http.StatusMethodNotAllowed, "method not allowed"}
+}
+
+// updateUser makes a user match the given SCIM representation. A user
+// that is made inactive is deactivated, in the same way as a
+// beanstalk_user whose on_destroy is "deactivate", since Beanstalk can't
+// disable a user's login.
+func (s *scimServer) updateUser(ctx context.Context, id int, req *scimUser) (interface{}, error) {
+	// The update starts from the user as stored so that attributes that
+	// SCIM doesn't know about, such as the timezone, are kept.
+	user, err := s.client.Users.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Active != nil && !*req.Active {
+		if user.IsAccountOwner {
+			return nil, &scimError{
+				http.StatusBadRequest,
+				"the account owner can't be deactivated; transfer ownership of the account first",
+			}
+		}
+		if err := beanstalk.DeactivateUser(ctx, s.client, id); err != nil {
+			return nil, err
+		}
+		user, err = s.client.Users.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.UserName != "" {
+		user.Username = req.UserName
+	}
+	if email := req.primaryEmail(); email != "" {
+		user.Email = email
+	}
+	if name := req.fullName(); name != "" {
+		user.Name = name
+		user.FirstName = ""
+		user.LastName = ""
+	}
+	if err := s.client.Users.Update(ctx, id, user); err != nil {
+		return nil, err
+	}
+
+	user, err = s.client.Users.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userResource(ctx, user)
+}
+
+// applyUserPatch applies the "replace" and "add" operations that identity
+// providers use to change users' attributes.
+func applyUserPatch(r *http.Request, user *scimUser) error {
+	req := &scimPatchRequest{}
+	if err := decodeSCIMBody(r, req); err != nil {
+		return err
+	}
+
+	for _, op := range req.Operations {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			return &scimError{http.StatusBadRequest, fmt.Sprintf("unsupported operation %q on users", op.Op)}
+		}
+
+		// Without a path, the value is a partial user.
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, user); err != nil {
+				return &scimError{http.StatusBadRequest, fmt.Sprintf("invalid value: %s", err)}
+			}
+			continue
+		}
+
+		// A pathless value may have removed the name entirely.
+		if user.Name == nil {
+			user.Name = &scimName{}
+		}
+
+		var target interface{}
+		switch strings.ToLower(op.Path) {
+		case "username":
+			target = &user.UserName
+		case "active":
+			target = &user.Active
+		case "name.formatted":
+			target = &user.Name.Formatted
+		case "name.givenname":
+			user.Name.Formatted = ""
+			target = &user.Name.GivenName
+		case "name.familyname":
+			user.Name.Formatted = ""
+			target = &user.Name.FamilyName
+		case "emails", `emails[type eq "work"].value`, `emails[primary eq true].value`:
+			var email string
+			if err := json.Unmarshal(op.Value, &email); err == nil {
+				user.Emails = []scimEmail{{Value: email, Primary: true}}
+				continue
+			}
+			target = &user.Emails
+		default:
+			return &scimError{http.StatusBadRequest, fmt.Sprintf("unsupported path %q on users", op.Path)}
+		}
+		if err := json.Unmarshal(op.Value, target); err != nil {
+			return &scimError{http.StatusBadRequest, fmt.Sprintf("invalid value for %s: %s", op.Path, err)}
+		}
+	}
+
+	return nil
+}
+
+func teamToSCIM(team *api.TeamRead) *scimGroup {
+	members := make([]scimMember, len(team.Users))
+	for i, user := range team.Users {
+		members[i] = scimMember{
+			Value:   strconv.Itoa(user.ID),
+			Display: user.Username,
+		}
+	}
+	return &scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          strconv.Itoa(team.ID),
+		DisplayName: team.Name,
+		Members:     members,
+		Meta: &scimMetadata{
+			ResourceType: "Group",
+			Location:     "/scim/v2/Groups/" + strconv.Itoa(team.ID),
+		},
+	}
+}
+
+func memberIds(members []scimMember) ([]int, error) {
+	ids := make([]int, len(members))
+	for i, member := range members {
+		id, err := strconv.Atoi(member.Value)
+		if err != nil {
+			return nil, &scimError{http.StatusBadRequest, fmt.Sprintf("invalid member %q", member.Value)}
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func (s *scimServer) listGroups(r *http.Request) (interface{}, error) {
+	attr, value, err := parseSCIMFilter(r, "displayName")
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []interface{}{}
+	err = s.client.Teams.Each(r.Context(), func(team *api.TeamRead) (bool, error) {
+		if attr == "displayName" && team.Name != value {
+			return true, nil
+		}
+		resources = append(resources, teamToSCIM(team))
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(r, resources), nil
+}
+
+func (s *scimServer) createGroup(r *http.Request) (interface{}, error) {
+	req := &scimGroup{}
+	if err := decodeSCIMBody(r, req); err != nil {
+		return nil, err
+	}
+	if req.DisplayName == "" {
+		return nil, &scimError{http.StatusBadRequest, "displayName is required"}
+	}
+
+	userIds, err := memberIds(req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	// Repository permissions aren't part of SCIM, so new teams have none
+	// until they are granted some by other means.
+	team, err := s.client.Teams.Create(r.Context(), &api.TeamWrite{
+		Name:        req.DisplayName,
+		UserIDs:     userIds,
+		Permissions: map[string]api.TeamRepositoryPermissionsWrite{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return teamToSCIM(team), nil
+}
+
+func (s *scimServer) handleGroup(r *http.Request, rawId string) (interface{}, error) {
+	id, err := parseSCIMId(rawId)
+	if err != nil {
+		return nil, err
+	}
+	ctx := r.Context()
+
+	if r.Method == "DELETE" {
+		return nil, s.client.Teams.Delete(ctx, id)
+	}
+
+	team, err := s.client.Teams.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Method {
+	case "GET":
+		return teamToSCIM(team), nil
+
+	case "PUT":
+		req := &scimGroup{}
+		if err := decodeSCIMBody(r, req); err != nil {
+			return nil, err
+		}
+		userIds, err := memberIds(req.Members)
+		if err != nil {
+			return nil, err
+		}
+
+		// The team's repository permissions are kept as they are.
+		write := team.ToWrite()
+		if req.DisplayName != "" {
+			write.Name = req.DisplayName
+		}
+		write.UserIDs = userIds
+		updated, err := s.client.Teams.Update(ctx, id, write)
+		if err != nil {
+			return nil, err
+		}
+		return teamToSCIM(updated), nil
+
+	case "PATCH":
+		write := team.ToWrite()
+		if err := applyGroupPatch(r, write); err != nil {
+			return nil, err
+		}
+		updated, err := s.client.Teams.Update(ctx, id, write)
+		if err != nil {
+			return nil, err
+		}
+		return teamToSCIM(updated), nil
+	}
+
+	return nil, &scimError{http.StatusMethodNotAllowed, "method not allowed"}
+}
+
+var scimMemberPathRegexp = regexp.MustCompile(`^members\[value eq "(\d+)"\]$`)
+
+// applyGroupPatch applies the operations that identity providers use to
+// rename groups and to add and remove their members.
+func applyGroupPatch(r *http.Request, team *api.TeamWrite) error {
+	req := &scimPatchRequest{}
+	if err := decodeSCIMBody(r, req); err != nil {
+		return err
+	}
+
+	removeMembers := func(ids []int) {
+		remove := map[int]bool{}
+		for _, id := range ids {
+			remove[id] = true
+		}
+		remaining := []int{}
+		for _, id := range team.UserIDs {
+			if !remove[id] {
+				remaining = append(remaining, id)
+			}
+		}
+		team.UserIDs = remaining
+	}
+
+	for _, op := range req.Operations {
+		opName := strings.ToLower(op.Op)
+		path := op.Path
+
+		if match := scimMemberPathRegexp.FindStringSubmatch(path); match != nil && opName == "remove" {
+			id, _ := strconv.Atoi(match[1])
+			removeMembers([]int{id})
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(path, "displayName") && opName == "replace":
+			if err := json.Unmarshal(op.Value, &team.Name); err != nil {
+				return &scimError{http.StatusBadRequest, fmt.Sprintf("invalid displayName: %s", err)}
+			}
+
+		case strings.EqualFold(path, "members"):
+			var members []scimMember
+			if len(op.Value) > 0 {
+				if err := json.Unmarshal(op.Value, &members); err != nil {
+					return &scimError{http.StatusBadRequest, fmt.Sprintf("invalid members: %s", err)}
+				}
+			}
+			ids, err := memberIds(members)
+			if err != nil {
+				return err
+			}
+
+			switch opName {
+			case "add":
+				removeMembers(ids)
+				team.UserIDs = append(team.UserIDs, ids...)
+			case "remove":
+				if len(op.Value) == 0 {
+					team.UserIDs = []int{}
+				} else {
+					removeMembers(ids)
+				}
+			case "replace":
+				team.UserIDs = ids
+			default:
+				return &scimError{http.StatusBadRequest, fmt.Sprintf("unsupported operation %q on members", op.Op)}
+			}
+
+		case path == "" && opName == "replace":
+			var partial scimGroup
+			if err := json.Unmarshal(op.Value, &partial); err != nil {
+				return &scimError{http.StatusBadRequest, fmt.Sprintf("invalid value: %s", err)}
+			}
+			if partial.DisplayName != "" {
+				team.Name = partial.DisplayName
+			}
+			if partial.Members != nil {
+				ids, err := memberIds(partial.Members)
+				if err != nil {
+					return err
+				}
+				team.UserIDs = ids
+			}
+
+		default:
+			return &scimError{http.StatusBadRequest, fmt.Sprintf("unsupported operation %q on path %q", op.Op, op.Path)}
+		}
+	}
+
+	return nil
+}
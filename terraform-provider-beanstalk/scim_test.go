@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/saymedia/terraform-beanstalk/beanstalk/api"
+)
+
+func newTestSCIMServer(t *testing.T) (*fakeBeanstalk, *scimServer) {
+	fake, client := newFakeBeanstalk(t)
+	return fake, &scimServer{client: client, token: "secret"}
+}
+
+// scimRequest sends a request to the server and decodes its response.
+func scimRequest(t *testing.T, s *scimServer, method, path, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	result := map[string]interface{}{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("%s %s returned invalid JSON %q: %s", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code, result
+}
+
+func expectStatus(t *testing.T, what string, got, want int, result map[string]interface{}) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("%s returned status %d, want %d; response was %v", what, got, want, result)
+	}
+}
+
+func TestSCIMRejectsUnauthorizedRequests(t *testing.T) {
+	_, s := newTestSCIMServer(t)
+
+	req := httptest.NewRequest("GET", "/scim/v2/Users", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("request with wrong token returned status %d, want 401", rec.Code)
+	}
+}
+
+func TestSCIMRejectsPathsOutsidePrefix(t *testing.T) {
+	_, s := newTestSCIMServer(t)
+
+	for _, path := range []string{"/Users", "/Groups/1", "/scim/v1/Users", "/scim/v2"} {
+		status, result := scimRequest(t, s, "GET", path, "")
+		expectStatus(t, "GET "+path, status, http.StatusNotFound, result)
+	}
+}
+
+func TestSCIMUsers(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+
+	status, created := scimRequest(t, s, "POST", "/scim/v2/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "jdoe",
+		"name": {"givenName": "Jane", "familyName": "Doe"},
+		"emails": [{"value": "jane@example.com", "primary": true}]
+	}`)
+	expectStatus(t, "create", status, http.StatusCreated, created)
+	id, _ := strconv.Atoi(created["id"].(string))
+	user := fake.user(id)
+	if user == nil || user.Username != "jdoe" || user.Name != "Jane Doe" || user.Email != "jane@example.com" {
+		t.Fatalf("created user is %+v", user)
+	}
+	if user.Timezone != "London" {
+		t.Errorf("creating user changed timezone to %q", user.Timezone)
+	}
+
+	status, got := scimRequest(t, s, "GET", "/scim/v2/Users/"+created["id"].(string), "")
+	expectStatus(t, "get", status, http.StatusOK, got)
+	if got["userName"] != "jdoe" {
+		t.Errorf("got userName %v, want jdoe", got["userName"])
+	}
+
+	status, updated := scimRequest(t, s, "PUT", "/scim/v2/Users/"+created["id"].(string), `{
+		"userName": "jdoe",
+		"name": {"formatted": "Jane Roe"},
+		"emails": [{"value": "jane.roe@example.com"}]
+	}`)
+	expectStatus(t, "replace", status, http.StatusOK, updated)
+	if user := fake.user(id); user.Name != "Jane Roe" || user.Email != "jane.roe@example.com" {
+		t.Errorf("replaced user is %+v", user)
+	}
+
+	status, result := scimRequest(t, s, "DELETE", "/scim/v2/Users/"+created["id"].(string), "")
+	expectStatus(t, "delete", status, http.StatusNoContent, result)
+	if fake.user(id) != nil {
+		t.Errorf("user still exists after delete")
+	}
+
+	status, result = scimRequest(t, s, "GET", "/scim/v2/Users/"+created["id"].(string), "")
+	expectStatus(t, "get deleted", status, http.StatusNotFound, result)
+}
+
+func TestSCIMCreateExistingUser(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	fake.addUser(api.User{Username: "jdoe", Email: "jane@example.com", Name: "Jane Doe"})
+
+	for what, body := range map[string]string{
+		"same login": `{"userName": "JDoe", "name": {"formatted": "J Doe"}, "emails": [{"value": "other@example.com"}]}`,
+		"same email": `{"userName": "jane", "name": {"formatted": "J Doe"}, "emails": [{"value": "Jane@Example.com"}]}`,
+	} {
+		status, result := scimRequest(t, s, "POST", "/scim/v2/Users", body)
+		expectStatus(t, "create with "+what, status, http.StatusConflict, result)
+		if result["scimType"] != "uniqueness" {
+			t.Errorf("create with %s returned scimType %v, want uniqueness", what, result["scimType"])
+		}
+	}
+}
+
+func TestSCIMUserUpdateKeepsAdmin(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	id := fake.addUser(api.User{Username: "boss", Email: "boss@example.com", Name: "The Boss", IsAccountAdmin: true})
+
+	status, result := scimRequest(t, s, "PATCH", "/scim/v2/Users/"+strconv.Itoa(id), `{
+		"Operations": [{"op": "replace", "path": "userName", "value": "chief"}]
+	}`)
+	expectStatus(t, "patch", status, http.StatusOK, result)
+	if user := fake.user(id); user.Username != "chief" || !user.IsAccountAdmin {
+		t.Errorf("patched user is %+v", user)
+	}
+}
+
+func TestSCIMListUsers(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	for _, login := range []string{"alice", "bob", "carol"} {
+		fake.addUser(api.User{Username: login, Email: login + "@example.com", Name: login})
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+		total int
+	}{
+		{"", []string{"alice", "bob", "carol"}, 3},
+		{`filter=userName eq "Bob"`, []string{"bob"}, 1},
+		{`filter=emails.value eq "carol@example.com"`, []string{"carol"}, 1},
+		{`filter=userName eq "nobody"`, []string{}, 0},
+		{"startIndex=2&count=1", []string{"bob"}, 3},
+		{"startIndex=5", []string{}, 3},
+		{"startIndex=2&count=9223372036854775807", []string{"bob", "carol"}, 3},
+	}
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test.query)
+		status, result := scimRequest(t, s, "GET", "/scim/v2/Users?"+query.Encode(), "")
+		expectStatus(t, "list "+test.query, status, http.StatusOK, result)
+
+		got := []string{}
+		for _, resource := range result["Resources"].([]interface{}) {
+			got = append(got, resource.(map[string]interface{})["userName"].(string))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("list %q returned %v, want %v", test.query, got, test.want)
+		}
+		if int(result["totalResults"].(float64)) != test.total {
+			t.Errorf("list %q reported %v results, want %d", test.query, result["totalResults"], test.total)
+		}
+	}
+
+	status, result := scimRequest(t, s, "GET", "/scim/v2/Users?"+url.Values{"filter": {`userName co "a"`}}.Encode(), "")
+	expectStatus(t, "unsupported filter", status, http.StatusBadRequest, result)
+}
+
+func TestSCIMListUsersWithoutPaging(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	fake.ignorePaging = true
+	for _, login := range []string{"alice", "bob"} {
+		fake.addUser(api.User{Username: login, Email: login + "@example.com", Name: login})
+	}
+
+	status, result := scimRequest(t, s, "GET", "/scim/v2/Users?"+url.Values{"filter": {`userName eq "nobody"`}}.Encode(), "")
+	expectStatus(t, "list missing user", status, http.StatusOK, result)
+	if int(result["totalResults"].(float64)) != 0 {
+		t.Errorf("list missing user reported %v results, want 0", result["totalResults"])
+	}
+
+	status, result = scimRequest(t, s, "GET", "/scim/v2/Users", "")
+	expectStatus(t, "list", status, http.StatusOK, result)
+	if int(result["totalResults"].(float64)) != 2 {
+		t.Errorf("list reported %v results, want 2", result["totalResults"])
+	}
+}
+
+func TestSCIMDeactivateUser(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	id := fake.addUser(api.User{Username: "leaver", Email: "leaver@example.com", Name: "Leaver"})
+	teamId := fake.addTeam(api.TeamWrite{Name: "Developers", UserIDs: []int{id}})
+	fake.addPermission(api.Permission{UserID: id, RepositoryID: 10, CanWrite: true})
+
+	status, got := scimRequest(t, s, "GET", "/scim/v2/Users/"+strconv.Itoa(id), "")
+	expectStatus(t, "get", status, http.StatusOK, got)
+	if got["active"] != true {
+		t.Errorf("user with access reported active=%v", got["active"])
+	}
+
+	status, got = scimRequest(t, s, "PATCH", "/scim/v2/Users/"+strconv.Itoa(id), `{
+		"Operations": [{"op": "replace", "path": "active", "value": false}]
+	}`)
+	expectStatus(t, "deactivate", status, http.StatusOK, got)
+	if got["active"] != false {
+		t.Errorf("deactivated user reported active=%v", got["active"])
+	}
+	if len(fake.team(teamId).UserIDs) != 0 {
+		t.Errorf("deactivated user is still in team: %v", fake.team(teamId).UserIDs)
+	}
+	if fake.permissionCount(id) != 0 {
+		t.Errorf("deactivated user still has permissions")
+	}
+}
+
+func TestSCIMDeactivateOwner(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	id := fake.addUser(api.User{Username: "owner", Email: "owner@example.com", Name: "Owner", IsAccountOwner: true})
+	teamId := fake.addTeam(api.TeamWrite{Name: "Developers", UserIDs: []int{id}})
+
+	status, result := scimRequest(t, s, "PUT", "/scim/v2/Users/"+strconv.Itoa(id), `{
+		"userName": "owner",
+		"active": false
+	}`)
+	expectStatus(t, "deactivate owner", status, http.StatusBadRequest, result)
+	if len(fake.team(teamId).UserIDs) != 1 {
+		t.Errorf("owner was removed from team")
+	}
+
+	status, result = scimRequest(t, s, "DELETE", "/scim/v2/Users/"+strconv.Itoa(id), "")
+	expectStatus(t, "delete owner", status, http.StatusBadRequest, result)
+	if fake.user(id) == nil {
+		t.Errorf("owner was deleted")
+	}
+}
+
+func TestSCIMPatchUserName(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	id := fake.addUser(api.User{Username: "jdoe", Email: "jane@example.com", Name: "Jane Doe"})
+
+	// The pathless operation removes the name, which the later ones must
+	// then recreate.
+	status, result := scimRequest(t, s, "PATCH", "/scim/v2/Users/"+strconv.Itoa(id), `{
+		"Operations": [
+			{"op": "replace", "value": {"name": null}},
+			{"op": "replace", "path": "name.givenName", "value": "Janet"},
+			{"op": "replace", "path": "name.familyName", "value": "Doe"}
+		]
+	}`)
+	expectStatus(t, "patch", status, http.StatusOK, result)
+	if user := fake.user(id); user.Name != "Janet Doe" {
+		t.Errorf("patched user has name %q, want Janet Doe", user.Name)
+	}
+
+	status, result = scimRequest(t, s, "PATCH", "/scim/v2/Users/"+strconv.Itoa(id), `{
+		"Operations": [{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "janet@example.com"}]
+	}`)
+	expectStatus(t, "patch email", status, http.StatusOK, result)
+	if user := fake.user(id); user.Email != "janet@example.com" {
+		t.Errorf("patched user has email %q", user.Email)
+	}
+
+	status, result = scimRequest(t, s, "PATCH", "/scim/v2/Users/"+strconv.Itoa(id), `{
+		"Operations": [{"op": "remove", "path": "userName"}]
+	}`)
+	expectStatus(t, "unsupported patch", status, http.StatusBadRequest, result)
+}
+
+func TestSCIMGroups(t *testing.T) {
+	fake, s := newTestSCIMServer(t)
+	alice := strconv.Itoa(fake.addUser(api.User{Username: "alice", Email: "alice@example.com"}))
+	bob := strconv.Itoa(fake.addUser(api.User{Username: "bob", Email: "bob@example.com"}))
+	carol := strconv.Itoa(fake.addUser(api.User{Username: "carol", Email: "carol@example.com"}))
+
+	status, created := scimRequest(t, s, "POST", "/scim/v2/Groups", `{
+		"displayName": "Developers",
+		"members": [{"value": "`+alice+`"}, {"value": "`+bob+`"}]
+	}`)
+	expectStatus(t, "create", status, http.StatusCreated, created)
+	path := "/scim/v2/Groups/" + created["id"].(string)
+	teamId, _ := strconv.Atoi(created["id"].(string))
+
+	// Permissions granted outside of SCIM must survive its changes.
+	fake.setTeamPermissions(teamId, map[string]api.TeamRepositoryPermissionsWrite{"10": {CanWrite: true}})
+
+	query := url.Values{"filter": {`displayName eq "Developers"`}}.Encode()
+	status, list := scimRequest(t, s, "GET", "/scim/v2/Groups?"+query, "")
+	expectStatus(t, "list", status, http.StatusOK, list)
+	if list["totalResults"].(float64) != 1 {
+		t.Errorf("filtered list returned %v groups, want 1", list["totalResults"])
+	}
+
+	status, result := scimRequest(t, s, "PATCH", path, `{
+		"Operations": [
+			{"op": "remove", "path": "members[value eq \"`+alice+`\"]"},
+			{"op": "add", "path": "members", "value": [{"value": "`+carol+`"}]}
+		]
+	}`)
+	expectStatus(t, "patch members", status, http.StatusOK, result)
+	if got, want := fake.team(teamId).UserIDs, memberIdsOf(t, bob, carol); !reflect.DeepEqual(got, want) {
+		t.Errorf("patched team has members %v, want %v", got, want)
+	}
+
+	status, result = scimRequest(t, s, "PATCH", path, `{
+		"Operations": [{"op": "replace", "path": "displayName", "value": "Engineers"}]
+	}`)
+	expectStatus(t, "patch name", status, http.StatusOK, result)
+	if fake.team(teamId).Name != "Engineers" {
+		t.Errorf("patched team has name %q", fake.team(teamId).Name)
+	}
+
+	status, result = scimRequest(t, s, "PUT", path, `{
+		"displayName": "Platform",
+		"members": [{"value": "`+alice+`"}]
+	}`)
+	expectStatus(t, "replace", status, http.StatusOK, result)
+	team := fake.team(teamId)
+	if team.Name != "Platform" || !reflect.DeepEqual(team.UserIDs, memberIdsOf(t, alice)) {
+		t.Errorf("replaced team is %+v", team)
+	}
+	if !team.Permissions["10"].CanWrite {
+		t.Errorf("replacing team lost its permissions: %v", team.Permissions)
+	}
+
+	status, result = scimRequest(t, s, "DELETE", path, "")
+	expectStatus(t, "delete", status, http.StatusNoContent, result)
+	if fake.team(teamId) != nil {
+		t.Errorf("team still exists after delete")
+	}
+}
+
+func memberIdsOf(t *testing.T, ids ...string) []int {
+	result := make([]int, len(ids))
+	for i, id := range ids {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[i] = n
+	}
+	return result
+}
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter    string
+		wantAttr  string
+		wantValue string
+		wantErr   bool
+	}{
+		{"", "", "", false},
+		{`userName eq "jdoe"`, "userName", "jdoe", false},
+		{`USERNAME eq "jdoe"`, "userName", "jdoe", false},
+		{`  userName   eq   "j d"  `, "userName", "j d", false},
+		{`userName eq "say \"hi\""`, "userName", `say "hi"`, false},
+		{`emails.value eq "a@example.com"`, "emails.value", "a@example.com", false},
+		{`displayName eq "Developers"`, "", "", true},
+		{`userName co "j"`, "", "", true},
+		{`userName eq jdoe`, "", "", true},
+		{`userName eq "a" and emails.value eq "b"`, "", "", true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/scim/v2/Users?"+url.Values{"filter": {test.filter}}.Encode(), nil)
+		attr, value, err := parseSCIMFilter(req, "userName", "emails.value")
+		if (err != nil) != test.wantErr {
+			t.Errorf("filter %q returned error %v, want error %v", test.filter, err, test.wantErr)
+			continue
+		}
+		if attr != test.wantAttr || value != test.wantValue {
+			t.Errorf("filter %q parsed as %q %q, want %q %q", test.filter, attr, value, test.wantAttr, test.wantValue)
+		}
+	}
+}